@@ -0,0 +1,109 @@
+package balancer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/serviceconfig"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+// Name 负载均衡名称
+const Name = "ego_weighted"
+
+func init() {
+	balancer.Register(&builder{})
+}
+
+// Config 负载均衡配置，通过service config的loadBalancingConfig传入
+type Config struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+
+	Deployment          string `json:"deployment"`          // 调用方的部署组，只访问相同部署组的实例
+	Zone                string `json:"zone"`                // 调用方所在的可用区
+	DisableZoneAffinity bool   `json:"disableZoneAffinity"` // 关闭同可用区优先
+}
+
+// ServiceConfig 使用该负载均衡的service config，传给grpc.WithDefaultServiceConfig
+func ServiceConfig(config Config) string {
+	content, _ := json.Marshal(config)
+	return fmt.Sprintf(`{"loadBalancingConfig":[{%q:%s}]}`, Name, content)
+}
+
+type builder struct{}
+
+// Build 每个连接一个balancer，resolver推送的节点信息保存在state中，picker每次选择时读取，
+// 权重、enable等信息变化不需要等待连接状态变化就能生效
+func (b *builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	st := &state{}
+	st.store(&snapshot{config: &Config{}})
+	return &weightedBalancer{
+		Balancer: base.NewBalancerBuilder(Name, &pickerBuilder{state: st}, base.Config{HealthCheck: true}).Build(cc, opts),
+		state:    st,
+	}
+}
+
+// Name ...
+func (b *builder) Name() string {
+	return Name
+}
+
+// ParseConfig ...
+func (b *builder) ParseConfig(content json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	config := &Config{}
+	if err := json.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("parse %s config fail, %w", Name, err)
+	}
+	return config, nil
+}
+
+type weightedBalancer struct {
+	balancer.Balancer
+	state *state
+}
+
+// UpdateClientConnState 先更新节点信息，再交给base balancer维护连接
+func (b *weightedBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	snap := &snapshot{
+		config: &Config{},
+		nodes:  make(map[string]server.ServiceInfo),
+		routes: make(map[string]eregistry.RouteConfig),
+	}
+	if config, ok := s.BalancerConfig.(*Config); ok {
+		snap.config = config
+	}
+	if attrs := s.ResolverState.Attributes; attrs != nil {
+		if routes, ok := attrs.Value(constant.KeyRouteConfig).(map[string]eregistry.RouteConfig); ok {
+			snap.routes = routes
+		}
+	}
+	for _, addr := range s.ResolverState.Addresses {
+		if addr.Attributes == nil {
+			continue
+		}
+		if info, ok := addr.Attributes.Value(constant.KeyServiceInfo).(server.ServiceInfo); ok {
+			snap.nodes[addr.Addr] = info
+		}
+	}
+	b.state.store(snap)
+	return b.Balancer.UpdateClientConnState(s)
+}
+
+// state 当前的节点信息和路由配置
+type state struct {
+	value atomic.Value // *snapshot
+}
+
+func (s *state) load() *snapshot {
+	return s.value.Load().(*snapshot)
+}
+
+func (s *state) store(snap *snapshot) {
+	s.value.Store(snap)
+}
@@ -0,0 +1,192 @@
+package balancer
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+// errNoAvailable 所有可用连接都被过滤时返回，不等待连接状态变化
+var errNoAvailable = status.Error(codes.Unavailable, "no available instance")
+
+type pickerBuilder struct {
+	state *state
+}
+
+// Build ...
+func (b *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	subConns := make(map[string]balancer.SubConn, len(info.ReadySCs))
+	for sc, scInfo := range info.ReadySCs {
+		subConns[scInfo.Address.Addr] = sc
+	}
+	return &picker{
+		state:    b.state,
+		subConns: subConns,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+type picker struct {
+	state    *state
+	subConns map[string]balancer.SubConn // 地址 => 已就绪的连接
+	mu       sync.Mutex
+	rand     *rand.Rand
+}
+
+// candidate 可以选择的节点
+type candidate struct {
+	addr   string
+	info   *server.ServiceInfo // 没有服务信息时为nil
+	weight float64
+}
+
+// Pick 依次按enable、部署组、路由配置、同可用区过滤节点，再按权重随机选择
+func (p *picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	snap := p.state.load()
+	candidates := snap.filter(p.subConns)
+	candidates = snap.route(info.FullMethodName, candidates, p.float64)
+	candidates = snap.preferZone(candidates)
+	if len(candidates) == 0 {
+		return balancer.PickResult{}, errNoAvailable
+	}
+	return balancer.PickResult{SubConn: p.subConns[pickWeighted(candidates, p.float64).addr]}, nil
+}
+
+func (p *picker) float64() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rand.Float64()
+}
+
+// snapshot resolver推送的节点信息、路由配置
+type snapshot struct {
+	config *Config
+	nodes  map[string]server.ServiceInfo    // 地址 => 服务信息
+	routes map[string]eregistry.RouteConfig // 路由配置
+}
+
+// filter 去掉关闭的、权重为0的、其他部署组的节点
+func (s *snapshot) filter(subConns map[string]balancer.SubConn) []candidate {
+	candidates := make([]candidate, 0, len(subConns))
+	for addr := range subConns {
+		c := candidate{addr: addr, weight: server.DefaultWeight}
+		if info, ok := s.nodes[addr]; ok {
+			if !info.Enable || info.Deployment != s.config.Deployment {
+				continue
+			}
+			c.info, c.weight = &info, info.Weight
+		}
+		if c.weight <= 0 {
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// route 按方法匹配路由配置。Upstream.Nodes不为空时只访问其中的节点并使用配置的权重，
+// Upstream.Groups不为空时先按权重选择流量组，再在组内选择节点
+func (s *snapshot) route(method string, candidates []candidate, random func() float64) []candidate {
+	config, ok := s.matchRoute(method)
+	if !ok {
+		return candidates
+	}
+	if len(config.Upstream.Nodes) > 0 {
+		weighted := make([]candidate, 0, len(candidates))
+		for _, c := range candidates {
+			if weight := config.Upstream.Nodes[c.addr]; weight > 0 {
+				c.weight = float64(weight)
+				weighted = append(weighted, c)
+			}
+		}
+		candidates = weighted
+	}
+	if len(config.Upstream.Groups) == 0 {
+		return candidates
+	}
+
+	groups := make(map[string][]candidate)
+	for _, c := range candidates {
+		if c.info == nil {
+			continue
+		}
+		if _, ok := config.Upstream.Groups[c.info.Group]; ok {
+			groups[c.info.Group] = append(groups[c.info.Group], c)
+		}
+	}
+	// 只在存在节点的流量组之间分配
+	choices := make([]candidate, 0, len(groups))
+	for group := range groups {
+		if weight := config.Upstream.Groups[group]; weight > 0 {
+			choices = append(choices, candidate{addr: group, weight: float64(weight)})
+		}
+	}
+	if len(choices) == 0 {
+		return nil
+	}
+	return groups[pickWeighted(choices, random).addr]
+}
+
+// matchRoute URI与方法名相同的路由优先，其次是URI为空或者*的默认路由，只使用调用方所在部署组的路由
+func (s *snapshot) matchRoute(method string) (eregistry.RouteConfig, bool) {
+	var (
+		fallback eregistry.RouteConfig
+		found    bool
+	)
+	for _, config := range s.routes {
+		if config.Deployment != "" && config.Deployment != s.config.Deployment {
+			continue
+		}
+		switch config.URI {
+		case method:
+			return config, true
+		case "", "*":
+			fallback, found = config, true
+		}
+	}
+	return fallback, found
+}
+
+// preferZone 存在同可用区的节点时只访问同可用区的节点
+func (s *snapshot) preferZone(candidates []candidate) []candidate {
+	if s.config.DisableZoneAffinity || s.config.Zone == "" {
+		return candidates
+	}
+	local := make([]candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.info != nil && c.info.Zone == s.config.Zone {
+			local = append(local, c)
+		}
+	}
+	if len(local) == 0 {
+		return candidates
+	}
+	return local
+}
+
+// pickWeighted 按权重随机选择，candidates不能为空
+func pickWeighted(candidates []candidate, random func() float64) candidate {
+	total := 0.0
+	for _, c := range candidates {
+		total += c.weight
+	}
+	target := random() * total
+	for _, c := range candidates {
+		if target < c.weight {
+			return c
+		}
+		target -= c.weight
+	}
+	return candidates[len(candidates)-1]
+}
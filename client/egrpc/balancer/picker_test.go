@@ -0,0 +1,140 @@
+package balancer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+type fakeSubConn struct {
+	addr string
+}
+
+func (f *fakeSubConn) UpdateAddresses([]resolver.Address) {}
+
+func (f *fakeSubConn) Connect() {}
+
+func node(addr string, fn func(info *server.ServiceInfo)) server.ServiceInfo {
+	info := server.ServiceInfo{Address: addr, Weight: 100, Enable: true}
+	if fn != nil {
+		fn(&info)
+	}
+	return info
+}
+
+// newPicker 所有节点都已就绪
+func newPicker(t *testing.T, config *Config, routes map[string]eregistry.RouteConfig, nodes ...server.ServiceInfo) balancer.Picker {
+	t.Helper()
+	b := &weightedBalancer{Balancer: nopBalancer{}, state: &state{}}
+	s := balancer.ClientConnState{
+		BalancerConfig: config,
+		ResolverState:  resolver.State{Attributes: attributes.New(constant.KeyRouteConfig, routes)},
+	}
+	info := base.PickerBuildInfo{ReadySCs: make(map[balancer.SubConn]base.SubConnInfo)}
+	for _, n := range nodes {
+		addr := resolver.Address{Addr: n.Address, Attributes: attributes.New(constant.KeyServiceInfo, n)}
+		s.ResolverState.Addresses = append(s.ResolverState.Addresses, addr)
+		info.ReadySCs[&fakeSubConn{addr: n.Address}] = base.SubConnInfo{Address: addr}
+	}
+	require.NoError(t, b.UpdateClientConnState(s))
+	return (&pickerBuilder{state: b.state}).Build(info)
+}
+
+// pickCount 统计每个节点被选中的次数
+func pickCount(t *testing.T, p balancer.Picker, method string, n int) map[string]int {
+	t.Helper()
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		res, err := p.Pick(balancer.PickInfo{FullMethodName: method})
+		require.NoError(t, err)
+		counts[res.SubConn.(*fakeSubConn).addr]++
+	}
+	return counts
+}
+
+type nopBalancer struct {
+	balancer.Balancer
+}
+
+func (nopBalancer) UpdateClientConnState(balancer.ClientConnState) error { return nil }
+
+func TestPickEnableAndWeight(t *testing.T) {
+	p := newPicker(t, &Config{}, nil,
+		node("a:1", nil),
+		node("b:1", func(info *server.ServiceInfo) { info.Weight = 300 }),
+		node("c:1", func(info *server.ServiceInfo) { info.Enable = false }),
+		node("d:1", func(info *server.ServiceInfo) { info.Weight = 0 }),
+	)
+	counts := pickCount(t, p, "/hello.Greeter/SayHello", 4000)
+	assert.Len(t, counts, 2)
+	assert.InDelta(t, 3.0, float64(counts["b:1"])/float64(counts["a:1"]), 0.5)
+}
+
+func TestPickDeployment(t *testing.T) {
+	nodes := []server.ServiceInfo{
+		node("a:1", nil),
+		node("b:1", func(info *server.ServiceInfo) { info.Deployment = "internal" }),
+	}
+	assert.Equal(t, map[string]int{"a:1": 100}, pickCount(t, newPicker(t, &Config{}, nil, nodes...), "/m", 100))
+	assert.Equal(t, map[string]int{"b:1": 100}, pickCount(t, newPicker(t, &Config{Deployment: "internal"}, nil, nodes...), "/m", 100))
+
+	_, err := newPicker(t, &Config{Deployment: "other"}, nil, nodes...).Pick(balancer.PickInfo{FullMethodName: "/m"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestPickRouteGroups(t *testing.T) {
+	nodes := []server.ServiceInfo{
+		node("a:1", func(info *server.ServiceInfo) { info.Group = "blue" }),
+		node("b:1", func(info *server.ServiceInfo) { info.Group = "green" }),
+		node("c:1", func(info *server.ServiceInfo) { info.Group = "red" }),
+	}
+	routes := map[string]eregistry.RouteConfig{
+		"default": {URI: "*", Upstream: eregistry.Upstream{Groups: map[string]int{"blue": 1, "green": 3}}},
+		"hello":   {URI: "/hello.Greeter/SayHello", Upstream: eregistry.Upstream{Groups: map[string]int{"red": 1}}},
+	}
+	p := newPicker(t, &Config{}, routes, nodes...)
+
+	counts := pickCount(t, p, "/hello.Greeter/Other", 4000)
+	assert.Len(t, counts, 2)
+	assert.InDelta(t, 3.0, float64(counts["b:1"])/float64(counts["a:1"]), 0.5)
+	assert.Equal(t, map[string]int{"c:1": 100}, pickCount(t, p, "/hello.Greeter/SayHello", 100))
+}
+
+func TestPickRouteNodes(t *testing.T) {
+	routes := map[string]eregistry.RouteConfig{
+		"nodes": {URI: "", Upstream: eregistry.Upstream{Nodes: map[string]int{"b:1": 10}}},
+	}
+	p := newPicker(t, &Config{}, routes, node("a:1", nil), node("b:1", nil))
+	assert.Equal(t, map[string]int{"b:1": 100}, pickCount(t, p, "/m", 100))
+}
+
+func TestPickZoneAffinity(t *testing.T) {
+	nodes := []server.ServiceInfo{
+		node("a:1", func(info *server.ServiceInfo) { info.Zone = "z1" }),
+		node("b:1", func(info *server.ServiceInfo) { info.Zone = "z2" }),
+	}
+	assert.Equal(t, map[string]int{"b:1": 100}, pickCount(t, newPicker(t, &Config{Zone: "z2"}, nil, nodes...), "/m", 100))
+	assert.Len(t, pickCount(t, newPicker(t, &Config{Zone: "z2", DisableZoneAffinity: true}, nil, nodes...), "/m", 1000), 2)
+	// 没有同可用区的节点时访问其他可用区
+	assert.Len(t, pickCount(t, newPicker(t, &Config{Zone: "z3"}, nil, nodes...), "/m", 1000), 2)
+}
+
+func TestServiceConfig(t *testing.T) {
+	content := ServiceConfig(Config{Deployment: "internal", Zone: "z1"})
+	assert.JSONEq(t, `{"loadBalancingConfig":[{"ego_weighted":{"deployment":"internal","zone":"z1","disableZoneAffinity":false}}]}`, content)
+
+	config, err := (&builder{}).ParseConfig([]byte(`{"deployment":"internal","zone":"z1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{Deployment: "internal", Zone: "z1"}, config)
+}
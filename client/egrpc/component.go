@@ -2,11 +2,17 @@ package egrpc
 
 import (
 	"context"
+	"net/url"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
 
+	"github.com/gotomicro/ego/client/egrpc/balancer"
+	_ "github.com/gotomicro/ego/client/egrpc/resolver" // 注册static、dnssrv
+	"github.com/gotomicro/ego/core/eapp"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
 )
 
 // PackageName 设置包名
@@ -42,7 +48,16 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(*config.keepAlive))
 	}
 
-	dialOptions = append(dialOptions, grpc.WithBalancerName(config.BalancerName))
+	if config.BalancerName == balancer.Name {
+		// ego_weighted需要通过service config传入调用方的部署组、可用区
+		dialOptions = append(dialOptions, grpc.WithDefaultServiceConfig(balancer.ServiceConfig(balancer.Config{
+			Deployment:          config.Deployment,
+			Zone:                eapp.AppZone(),
+			DisableZoneAffinity: !config.EnableZoneAffinity,
+		})))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithBalancerName(config.BalancerName))
+	}
 
 	cc, err := grpc.DialContext(ctx, config.Addr, dialOptions...)
 
@@ -53,6 +68,9 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 			logger.Error("dial grpc server", elog.FieldErrKind("request err"), elog.FieldErr(err))
 		}
 	}
+	if config.EnableRegisterConsumer {
+		registerConsumer(config.Addr, logger)
+	}
 	logger.Info("start grpc client", elog.FieldName(name))
 	return &Component{
 		name:       name,
@@ -61,3 +79,18 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 		ClientConn: cc,
 	}
 }
+
+// registerConsumer Addr为服务发现地址时，将本应用注册为服务的消费方，注册失败时由Lease重试
+func registerConsumer(addr string, logger *elog.Component) {
+	target, err := url.Parse(addr)
+	if err != nil {
+		return
+	}
+	if _, ok := eregistry.Get(target.Scheme); !ok {
+		return
+	}
+	service := strings.Trim(target.Path, "/")
+	if err := eregistry.RegisterConsumer(context.Background(), target.Scheme, service, "grpc"); err != nil {
+		logger.Error("register consumer", elog.FieldName(service), elog.FieldErr(err))
+	}
+}
@@ -12,8 +12,9 @@ import (
 
 // Config ...
 type Config struct {
-	Addr                       string        // 连接地址，直连为127.0.0.1:9001，服务发现为etcd:///appname
-	BalancerName               string        // 负载均衡方式，默认round robin
+	Addr                       string        // 连接地址，直连为127.0.0.1:9001，多个地址为static:///127.0.0.1:9001,127.0.0.1:9002，DNS SRV为dnssrv:///_grpc._tcp.svc，服务发现为etcd:///appname
+	BalancerName               string        // 负载均衡方式，默认round robin，设置为ego_weighted时按enable、权重、部署组、流量组、可用区选择节点
+	Deployment                 string        // 调用方的部署组，使用ego_weighted时只访问相同部署组的节点
 	OnFail                     string        // 失败后的处理方式，panic | error
 	DialTimeout                time.Duration // 连接超时，默认3s
 	ReadTimeout                time.Duration // 读超时，默认1s
@@ -28,6 +29,8 @@ type Config struct {
 	EnableAccessInterceptor    bool          // 是否开启记录请求数据，默认不开启
 	EnableAccessInterceptorReq bool          // 是否开启记录请求参数，默认不开启
 	EnableAccessInterceptorRes bool          // 是否开启记录响应参数，默认不开启
+	EnableZoneAffinity         bool          // 使用ego_weighted时是否优先访问同可用区的节点，默认不开启
+	EnableRegisterConsumer     bool          // 服务发现时是否将本应用注册为服务的消费方，默认不开启
 
 	keepAlive   *keepalive.ClientParameters
 	dialOptions []grpc.DialOption
@@ -52,5 +55,6 @@ func DefaultConfig() *Config {
 		EnableAccessInterceptor:    false,
 		EnableAccessInterceptorReq: false,
 		EnableAccessInterceptorRes: false,
+		EnableZoneAffinity:         false,
 	}
 }
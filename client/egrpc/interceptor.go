@@ -7,7 +7,6 @@ import (
 	"log"
 	"time"
 
-	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -36,10 +35,10 @@ func metricUnaryClientInterceptor(name string) func(ctx context.Context, method
 		if spbStatus.Code < ecode.EcodeNum {
 			// 只记录系统级别的详细错误码
 			emetric.ClientHandleCounter.Inc(emetric.TypeGRPCUnary, name, method, cc.Target(), spbStatus.GetMessage())
-			emetric.ClientHandleHistogram.Observe(time.Since(beg).Seconds(), emetric.TypeGRPCUnary, name, method, cc.Target())
+			emetric.ClientHandleHistogram.ObserveWithTrace(time.Since(beg).Seconds(), etrace.ExtractTraceID(ctx), emetric.TypeGRPCUnary, name, method, cc.Target())
 		} else {
 			emetric.ClientHandleCounter.Inc(emetric.TypeGRPCUnary, name, method, cc.Target(), "biz error")
-			emetric.ClientHandleHistogram.Observe(time.Since(beg).Seconds(), emetric.TypeGRPCUnary, name, method, cc.Target())
+			emetric.ClientHandleHistogram.ObserveWithTrace(time.Since(beg).Seconds(), etrace.ExtractTraceID(ctx), emetric.TypeGRPCUnary, name, method, cc.Target())
 		}
 		return err
 	}
@@ -164,7 +163,7 @@ func loggerUnaryClientInterceptor(_logger *elog.Component, config *Config) grpc.
 		)
 
 		// 开启了链路，那么就记录链路id
-		if config.EnableTraceInterceptor && opentracing.IsGlobalTracerRegistered() {
+		if config.EnableTraceInterceptor && etrace.IsGlobalTracerRegistered() {
 			fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
 		}
 
@@ -27,6 +27,27 @@ func WithBalancerName(balancerName string) Option {
 	}
 }
 
+// WithDeployment setting the deployment of caller, only instances in the same deployment will be picked
+func WithDeployment(deployment string) Option {
+	return func(c *Container) {
+		c.config.Deployment = deployment
+	}
+}
+
+// WithEnableZoneAffinity setting whether ego_weighted prefers instances in the same zone
+func WithEnableZoneAffinity(enableZoneAffinity bool) Option {
+	return func(c *Container) {
+		c.config.EnableZoneAffinity = enableZoneAffinity
+	}
+}
+
+// WithEnableRegisterConsumer setting whether to register as a consumer of the target service
+func WithEnableRegisterConsumer(enableRegisterConsumer bool) Option {
+	return func(c *Container) {
+		c.config.EnableRegisterConsumer = enableRegisterConsumer
+	}
+}
+
 // WithDialTimeout setting grpc dial timeout
 func WithDialTimeout(t time.Duration) Option {
 	return func(c *Container) {
@@ -0,0 +1,166 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/util/xgo"
+)
+
+const (
+	// SchemeDNSSRV 通过DNS SRV记录发现节点，例如 dnssrv:///_grpc._tcp.svc.cluster.local
+	SchemeDNSSRV = "dnssrv"
+	// DefaultDNSSRVRefreshInterval 默认刷新间隔
+	DefaultDNSSRVRefreshInterval = 30 * time.Second
+
+	dnsLookupTimeout = 5 * time.Second
+	// minDNSSRVResolveInterval 两次查询的最小间隔，与grpc内置dns resolver一致，刷新间隔更小时使用刷新间隔
+	minDNSSRVResolveInterval = 30 * time.Second
+)
+
+// RegisterDNSSRV 使用指定的刷新间隔重新注册dnssrv
+func RegisterDNSSRV(refresh time.Duration) {
+	resolver.Register(newDNSSRVBuilder(refresh))
+}
+
+type lookupSRVFunc func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+
+type dnsSRVBuilder struct {
+	refresh     time.Duration
+	minInterval time.Duration
+	lookupSRV   lookupSRVFunc
+}
+
+func newDNSSRVBuilder(refresh time.Duration) *dnsSRVBuilder {
+	if refresh <= 0 {
+		refresh = DefaultDNSSRVRefreshInterval
+	}
+	minInterval := minDNSSRVResolveInterval
+	if refresh < minInterval {
+		minInterval = refresh
+	}
+	return &dnsSRVBuilder{refresh: refresh, minInterval: minInterval, lookupSRV: net.DefaultResolver.LookupSRV}
+}
+
+// Build 在后台定时查询SRV记录，记录变化时推送。首次查询同样在后台进行，不阻塞Dial
+func (b *dnsSRVBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	r := &dnsSRVResolver{
+		name:        target.Endpoint,
+		cc:          cc,
+		refresh:     b.refresh,
+		minInterval: b.minInterval,
+		lookupSRV:   b.lookupSRV,
+		resolveNow:  make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+	}
+	xgo.Go(r.watch)
+	return r, nil
+}
+
+// Scheme ...
+func (b *dnsSRVBuilder) Scheme() string {
+	return SchemeDNSSRV
+}
+
+type dnsSRVResolver struct {
+	name        string
+	cc          resolver.ClientConn
+	refresh     time.Duration
+	minInterval time.Duration
+	lookupSRV   lookupSRVFunc
+	resolveNow  chan struct{}
+	stop        chan struct{}
+	last        map[string]float64 // 上次推送的地址 => 权重
+}
+
+// ResolveNow 连接失败时grpc会调用，距上次查询超过minInterval时立即重新查询，否则等到间隔满足后查询一次
+func (r *dnsSRVResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+// Close ...
+func (r *dnsSRVResolver) Close() {
+	close(r.stop)
+}
+
+func (r *dnsSRVResolver) watch() {
+	ticker := time.NewTicker(r.refresh)
+	defer ticker.Stop()
+	for {
+		r.resolve()
+		// 两次查询至少间隔minInterval，期间的多次ResolveNow合并为一次，避免重连风暴时频繁查询
+		select {
+		case <-time.After(r.minInterval):
+		case <-r.stop:
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-r.resolveNow:
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// resolve 查询失败时保留上次的结果
+func (r *dnsSRVResolver) resolve() {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsLookupTimeout)
+	defer cancel()
+	_, records, err := r.lookupSRV(ctx, "", "", r.name)
+	if err != nil {
+		elog.EgoLogger.Error("lookup srv fail", elog.FieldComponent("resolver.dnssrv"), elog.FieldName(r.name), elog.FieldErr(err))
+		r.cc.ReportError(err)
+		return
+	}
+	nodes := srvNodes(records)
+	if reflect.DeepEqual(nodes, r.last) {
+		return
+	}
+	r.last = nodes
+
+	endpoints := eregistry.NewEndpoints()
+	for addr, weight := range nodes {
+		endpoints.Nodes[addr] = newNode(r.name, addr, weight)
+	}
+	if err := r.cc.UpdateState(newState(*endpoints, "")); err != nil {
+		elog.EgoLogger.Error("update state fail", elog.FieldComponent("resolver.dnssrv"), elog.FieldName(r.name), elog.FieldErr(err))
+	}
+}
+
+// srvNodes 只使用priority最小的记录，权重为0的记录按1处理，避免被负载均衡忽略
+func srvNodes(records []*net.SRV) map[string]float64 {
+	nodes := make(map[string]float64, len(records))
+	if len(records) == 0 {
+		return nodes
+	}
+	priority := records[0].Priority
+	for _, record := range records {
+		if record.Priority < priority {
+			priority = record.Priority
+		}
+	}
+	for _, record := range records {
+		if record.Priority != priority {
+			continue
+		}
+		weight := float64(record.Weight)
+		if weight == 0 {
+			weight = 1
+		}
+		addr := net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port)))
+		nodes[addr] = weight
+	}
+	return nodes
+}
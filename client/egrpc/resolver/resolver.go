@@ -2,15 +2,18 @@ package resolver
 
 import (
 	"context"
+
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/resolver"
+
 	"github.com/gotomicro/ego/core/constant"
 	"github.com/gotomicro/ego/core/eregistry"
 	"github.com/gotomicro/ego/core/util/xgo"
-	"google.golang.org/grpc/attributes"
-	"google.golang.org/grpc/resolver"
 )
 
-// Register ...
+// Register 注册grpc的resolver，同时通过eregistry.Register注册，ehttp也可以使用该注册中心发现节点
 func Register(name string, reg eregistry.Registry) {
+	eregistry.Register(name, reg)
 	resolver.Register(&baseBuilder{
 		name: name,
 		reg:  reg,
@@ -24,8 +27,11 @@ type baseBuilder struct {
 
 // Build ...
 func (b *baseBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
-	endpoints, err := b.reg.WatchServices(context.Background(), target.Endpoint, "grpc")
+	// resolver关闭时取消watch
+	ctx, cancel := context.WithCancel(context.Background())
+	endpoints, err := b.reg.WatchServices(ctx, target.Endpoint, "grpc")
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
@@ -33,23 +39,12 @@ func (b *baseBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts
 	xgo.Go(func() {
 		for {
 			select {
-			case endpoint := <-endpoints:
-				var state = resolver.State{
-					Addresses: make([]resolver.Address, 0),
-					Attributes: attributes.New(
-						constant.KeyRouteConfig, endpoint.RouteConfigs, // 路由配置
-						constant.KeyProviderConfig, endpoint.ProviderConfigs, // 服务提供方元信息
-						constant.KeyConsumerConfig, endpoint.ConsumerConfigs, // 服务消费方配置信息
-					),
+			case endpoint, ok := <-endpoints:
+				// 注册中心关闭了watch
+				if !ok {
+					return
 				}
-				for _, node := range endpoint.Nodes {
-					var address resolver.Address
-					address.Addr = node.Address
-					address.ServerName = target.Endpoint
-					address.Attributes = attributes.New(constant.KeyServiceInfo, node)
-					state.Addresses = append(state.Addresses, address)
-				}
-				cc.UpdateState(state)
+				cc.UpdateState(newState(endpoint, target.Endpoint))
 			case <-stop:
 				return
 			}
@@ -57,21 +52,46 @@ func (b *baseBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts
 	})
 
 	return &baseResolver{
-		stop: stop,
+		stop:   stop,
+		cancel: cancel,
 	}, nil
 }
 
+// newState 将节点信息、路由配置等放到Attributes中，供负载均衡使用
+func newState(endpoint eregistry.Endpoints, serverName string) resolver.State {
+	var state = resolver.State{
+		Addresses: make([]resolver.Address, 0, len(endpoint.Nodes)),
+		Attributes: attributes.New(
+			constant.KeyRouteConfig, endpoint.RouteConfigs, // 路由配置
+			constant.KeyProviderConfig, endpoint.ProviderConfigs, // 服务提供方元信息
+			constant.KeyConsumerConfig, endpoint.ConsumerConfigs, // 服务消费方配置信息
+		),
+	}
+	for _, node := range endpoint.Nodes {
+		var address resolver.Address
+		address.Addr = node.Address
+		address.ServerName = serverName
+		address.Attributes = attributes.New(constant.KeyServiceInfo, node)
+		state.Addresses = append(state.Addresses, address)
+	}
+	return state
+}
+
 // Scheme ...
 func (b baseBuilder) Scheme() string {
 	return b.name
 }
 
 type baseResolver struct {
-	stop chan struct{}
+	stop   chan struct{}
+	cancel context.CancelFunc
 }
 
 // ResolveNow ...
 func (b *baseResolver) ResolveNow(options resolver.ResolveNowOptions) {}
 
 // Close ...
-func (b *baseResolver) Close() { b.stop <- struct{}{} }
+func (b *baseResolver) Close() {
+	b.cancel()
+	close(b.stop)
+}
@@ -0,0 +1,181 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+type fakeClientConn struct {
+	resolver.ClientConn
+	mu     sync.Mutex
+	states []resolver.State
+	errs   []error
+}
+
+func (f *fakeClientConn) UpdateState(state resolver.State) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states = append(f.states, state)
+	return nil
+}
+
+func (f *fakeClientConn) ReportError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs = append(f.errs, err)
+}
+
+func (f *fakeClientConn) lastState() (resolver.State, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.states) == 0 {
+		return resolver.State{}, 0
+	}
+	return f.states[len(f.states)-1], len(f.states)
+}
+
+// nodes 按地址排序返回state中的节点信息
+func nodes(t *testing.T, state resolver.State) []server.ServiceInfo {
+	t.Helper()
+	infos := make([]server.ServiceInfo, 0, len(state.Addresses))
+	for _, addr := range state.Addresses {
+		info, ok := addr.Attributes.Value(constant.KeyServiceInfo).(server.ServiceInfo)
+		require.True(t, ok)
+		assert.Equal(t, info.Address, addr.Addr)
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Address < infos[j].Address })
+	return infos
+}
+
+func TestStatic(t *testing.T) {
+	cc := &fakeClientConn{}
+	r, err := resolver.Get(SchemeStatic).Build(resolver.Target{Scheme: SchemeStatic, Endpoint: "a:1, b:2,"}, cc, resolver.BuildOptions{})
+	require.NoError(t, err)
+	defer r.Close()
+
+	state, n := cc.lastState()
+	assert.Equal(t, 1, n)
+	infos := nodes(t, state)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "a:1", infos[0].Address)
+	assert.Equal(t, "b:2", infos[1].Address)
+	assert.True(t, infos[0].Enable)
+	assert.Equal(t, float64(100), infos[0].Weight)
+	_, ok := state.Attributes.Value(constant.KeyRouteConfig).(map[string]eregistry.RouteConfig)
+	assert.True(t, ok)
+
+	_, err = resolver.Get(SchemeStatic).Build(resolver.Target{Scheme: SchemeStatic, Endpoint: ""}, cc, resolver.BuildOptions{})
+	assert.Error(t, err)
+}
+
+type fakeDNS struct {
+	mu      sync.Mutex
+	records []*net.SRV
+	err     error
+	names   []string
+}
+
+func (f *fakeDNS) lookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.names = append(f.names, name)
+	return name, f.records, f.err
+}
+
+func (f *fakeDNS) set(records []*net.SRV, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records, f.err = records, err
+}
+
+func TestDNSSRV(t *testing.T) {
+	dns := &fakeDNS{records: []*net.SRV{
+		{Target: "a.svc.", Port: 9001, Priority: 10, Weight: 30},
+		{Target: "b.svc.", Port: 9001, Priority: 10, Weight: 0},
+		{Target: "backup.svc.", Port: 9001, Priority: 20, Weight: 50},
+	}}
+	builder := newDNSSRVBuilder(20 * time.Millisecond)
+	builder.lookupSRV = dns.lookupSRV
+	cc := &fakeClientConn{}
+	r, err := builder.Build(resolver.Target{Scheme: SchemeDNSSRV, Endpoint: "_grpc._tcp.svc"}, cc, resolver.BuildOptions{})
+	require.NoError(t, err)
+	defer r.Close()
+
+	// 首次查询在后台进行
+	assert.Eventually(t, func() bool {
+		_, n := cc.lastState()
+		return n == 1
+	}, time.Second, 5*time.Millisecond)
+	state, _ := cc.lastState()
+	infos := nodes(t, state)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "a.svc:9001", infos[0].Address)
+	assert.Equal(t, float64(30), infos[0].Weight)
+	assert.Equal(t, "b.svc:9001", infos[1].Address)
+	assert.Equal(t, float64(1), infos[1].Weight)
+	dns.mu.Lock()
+	assert.Equal(t, "_grpc._tcp.svc", dns.names[0])
+	dns.mu.Unlock()
+
+	// 记录没有变化时不推送，查询失败时保留原有节点
+	dns.set(nil, errors.New("timeout"))
+	time.Sleep(100 * time.Millisecond)
+	_, n := cc.lastState()
+	assert.Equal(t, 1, n)
+	cc.mu.Lock()
+	assert.NotEmpty(t, cc.errs)
+	cc.mu.Unlock()
+
+	dns.set([]*net.SRV{{Target: "c.svc.", Port: 9002, Priority: 1, Weight: 10}}, nil)
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	assert.Eventually(t, func() bool {
+		state, _ := cc.lastState()
+		infos := nodes(t, state)
+		return len(infos) == 1 && infos[0].Address == "c.svc:9002"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestDNSSRVResolveNowRateLimit(t *testing.T) {
+	dns := &fakeDNS{records: []*net.SRV{{Target: "a.svc.", Port: 9001, Priority: 10, Weight: 10}}}
+	builder := newDNSSRVBuilder(time.Hour)
+	builder.lookupSRV = dns.lookupSRV
+	builder.minInterval = 100 * time.Millisecond
+	cc := &fakeClientConn{}
+	r, err := builder.Build(resolver.Target{Scheme: SchemeDNSSRV, Endpoint: "_grpc._tcp.svc"}, cc, resolver.BuildOptions{})
+	require.NoError(t, err)
+	defer r.Close()
+	lookups := func() int {
+		dns.mu.Lock()
+		defer dns.mu.Unlock()
+		return len(dns.names)
+	}
+	assert.Eventually(t, func() bool {
+		return lookups() == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// 间隔内的多次ResolveNow合并为一次查询
+	for i := 0; i < 100; i++ {
+		r.ResolveNow(resolver.ResolveNowOptions{})
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, lookups())
+	assert.Eventually(t, func() bool {
+		return lookups() == 2
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, 2, lookups())
+}
@@ -0,0 +1,68 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/resolver"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+// SchemeStatic 静态地址列表，例如 static:///127.0.0.1:9001,127.0.0.1:9002
+const SchemeStatic = "static"
+
+func init() {
+	resolver.Register(&staticBuilder{})
+	resolver.Register(newDNSSRVBuilder(DefaultDNSSRVRefreshInterval))
+}
+
+type staticBuilder struct{}
+
+// Build 地址列表不会变化，构造时推送一次
+func (b *staticBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	endpoints := eregistry.NewEndpoints()
+	for _, addr := range strings.Split(target.Endpoint, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		endpoints.Nodes[addr] = newNode(target.Endpoint, addr, server.DefaultWeight)
+	}
+	if len(endpoints.Nodes) == 0 {
+		return nil, fmt.Errorf("static resolver: no address in target %q", target.Endpoint)
+	}
+	if err := cc.UpdateState(newState(*endpoints, "")); err != nil {
+		return nil, err
+	}
+	return staticResolver{}, nil
+}
+
+// Scheme ...
+func (b *staticBuilder) Scheme() string {
+	return SchemeStatic
+}
+
+type staticResolver struct{}
+
+// ResolveNow ...
+func (staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+// Close ...
+func (staticResolver) Close() {}
+
+// newNode 不经过注册中心的节点，默认开启
+func newNode(name, addr string, weight float64) server.ServiceInfo {
+	return server.ServiceInfo{
+		Name:     name,
+		Scheme:   "grpc",
+		Address:  addr,
+		Weight:   weight,
+		Enable:   true,
+		Healthy:  true,
+		Metadata: make(map[string]string),
+		Kind:     constant.ServiceProvider,
+	}
+}
@@ -0,0 +1,229 @@
+package ehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/util/xgo"
+	"github.com/gotomicro/ego/server"
+)
+
+// initialResolveTimeout 构造组件时等待注册中心第一次推送的时间
+const initialResolveTimeout = 3 * time.Second
+
+// errNoAvailableNode 没有可用节点
+var errNoAvailableNode = errors.New("no available node")
+
+// target 服务发现地址{registry}:///{服务名}
+type target struct {
+	registry string // 注册中心名称
+	reg      eregistry.Registry
+	service  string
+}
+
+// parseTarget Addr为{registry}:///{服务名}并且registry已经注册时，返回注册中心和服务名
+func parseTarget(addr string) (target, bool) {
+	u, err := url.Parse(addr)
+	if err != nil || u.Scheme == "" || u.Host != "" {
+		return target{}, false
+	}
+	reg, ok := eregistry.Get(u.Scheme)
+	if !ok {
+		return target{}, false
+	}
+	service := strings.Trim(u.Path, "/")
+	return target{registry: u.Scheme, reg: reg, service: service}, service != ""
+}
+
+// balancer 按权重在注册中心推送的http节点之间选择，连续失败的节点暂时摘除
+type balancer struct {
+	service       string
+	cancel        context.CancelFunc // 停止watch
+	logger        *elog.Component
+	ejectFailures int
+	ejectDuration time.Duration
+
+	mu     sync.Mutex
+	nodes  []server.ServiceInfo  // 开启的节点
+	states map[string]*nodeState // 地址 => 被动摘除状态，节点更新后保留
+	rand   *rand.Rand
+}
+
+type nodeState struct {
+	failures     int
+	ejectedUntil time.Time
+}
+
+// newBalancer watch服务的http节点，等待第一次推送后返回
+func newBalancer(reg eregistry.Registry, service string, config *Config, logger *elog.Component) (*balancer, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	endpoints, err := reg.WatchServices(ctx, service, "http")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	b := &balancer{
+		service:       service,
+		cancel:        cancel,
+		logger:        logger,
+		ejectFailures: config.EjectFailures,
+		ejectDuration: config.EjectDuration,
+		states:        make(map[string]*nodeState),
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	select {
+	case endpoint, ok := <-endpoints:
+		if ok {
+			b.update(endpoint)
+		}
+	case <-time.After(initialResolveTimeout):
+		logger.Warn("wait for nodes timeout", elog.FieldName(service))
+	}
+	xgo.Go(func() {
+		for endpoint := range endpoints {
+			b.update(endpoint)
+		}
+	})
+	return b, nil
+}
+
+// close 停止watch，注册中心关闭channel后不再更新节点
+func (b *balancer) close() {
+	b.cancel()
+}
+
+// update 更新节点列表，去掉关闭的、权重为0的节点
+func (b *balancer) update(endpoint eregistry.Endpoints) {
+	nodes := make([]server.ServiceInfo, 0, len(endpoint.Nodes))
+	for _, node := range endpoint.Nodes {
+		if node.Enable && node.Weight > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nodes = nodes
+	states := make(map[string]*nodeState, len(nodes))
+	for _, node := range nodes {
+		if state, ok := b.states[node.Address]; ok {
+			states[node.Address] = state
+		}
+	}
+	b.states = states
+	b.logger.Info("update nodes", elog.FieldName(b.service), elog.FieldValueAny(len(nodes)))
+}
+
+// pick 按权重随机选择未被摘除的节点，所有节点都被摘除时在全部节点中选择
+func (b *balancer) pick() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	candidates := make([]server.ServiceInfo, 0, len(b.nodes))
+	for _, node := range b.nodes {
+		if state, ok := b.states[node.Address]; ok && now.Before(state.ejectedUntil) {
+			continue
+		}
+		candidates = append(candidates, node)
+	}
+	if len(candidates) == 0 {
+		candidates = b.nodes
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%w for service %s", errNoAvailableNode, b.service)
+	}
+	total := 0.0
+	for _, node := range candidates {
+		total += node.Weight
+	}
+	target := b.rand.Float64() * total
+	for _, node := range candidates {
+		if target < node.Weight {
+			return node.Address, nil
+		}
+		target -= node.Weight
+	}
+	return candidates[len(candidates)-1].Address, nil
+}
+
+// report 记录请求结果，连续失败ejectFailures次后摘除ejectDuration
+func (b *balancer) report(addr string, success bool) {
+	if b.ejectFailures <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[addr]
+	if !ok {
+		if success || !b.hasNodeLocked(addr) {
+			return
+		}
+		state = &nodeState{}
+		b.states[addr] = state
+	}
+	if success {
+		state.failures = 0
+		return
+	}
+	state.failures++
+	if state.failures >= b.ejectFailures {
+		state.failures = 0
+		state.ejectedUntil = time.Now().Add(b.ejectDuration)
+		b.logger.Warn("eject node", elog.FieldName(b.service), elog.FieldAddr(addr), elog.FieldValueAny(b.ejectDuration.String()))
+	}
+}
+
+func (b *balancer) hasNodeLocked(addr string) bool {
+	for _, node := range b.nodes {
+		if node.Address == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// balancerTransport 每次请求(包括重试)选择一个节点替换服务名，并根据请求结果判断节点是否可用，
+// 连接错误和5xx视为失败，调用方取消的请求不计入
+type balancerTransport struct {
+	next     http.RoundTripper
+	balancer *balancer
+}
+
+// RoundTrip ...
+func (t *balancerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.balancer.service {
+		return t.next.RoundTrip(req)
+	}
+	addr, err := t.balancer.pick()
+	if err != nil {
+		return nil, err
+	}
+	// RoundTripper不能修改原请求
+	req = req.Clone(req.Context())
+	// 没有通过header指定Host时，Host使用节点地址
+	if req.Host == req.URL.Host {
+		req.Host = ""
+	}
+	req.URL.Host = addr
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		if req.Context().Err() == nil {
+			t.balancer.report(addr, false)
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.balancer.report(addr, false)
+	default:
+		t.balancer.report(addr, true)
+	}
+	return resp, err
+}
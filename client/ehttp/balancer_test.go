@@ -0,0 +1,144 @@
+package ehttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/eregistry/ememory"
+	"github.com/gotomicro/ego/server"
+)
+
+func newNode(t *testing.T, store *ememory.Store, status int) (*httptest.Server, *server.ServiceInfo) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(r.Host))
+	}))
+	t.Cleanup(srv.Close)
+	info := &server.ServiceInfo{
+		Name:    "user-svc",
+		Scheme:  "http",
+		Address: strings.TrimPrefix(srv.URL, "http://"),
+		Weight:  100,
+		Enable:  true,
+		Kind:    constant.ServiceProvider,
+	}
+	require.NoError(t, store.RegisterService(context.Background(), info))
+	return srv, info
+}
+
+// hosts 请求n次，返回每个节点被访问的次数
+func hosts(t *testing.T, client *Component, n int) map[string]int {
+	t.Helper()
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		res, err := client.R().Get("/hello")
+		require.NoError(t, err)
+		counts[res.String()]++
+	}
+	return counts
+}
+
+func TestParseTarget(t *testing.T) {
+	eregistry.Register("parse", ememory.NewStore())
+	target, ok := parseTarget("parse:///user-svc")
+	assert.True(t, ok)
+	assert.Equal(t, "parse", target.registry)
+	assert.Equal(t, "user-svc", target.service)
+
+	_, ok = parseTarget("parse:///")
+	assert.False(t, ok)
+	_, ok = parseTarget("unknown:///user-svc")
+	assert.False(t, ok)
+	_, ok = parseTarget("http://127.0.0.1:9001")
+	assert.False(t, ok)
+}
+
+func TestRegistryDiscovery(t *testing.T) {
+	store := ememory.NewStore()
+	defer store.Close()
+	eregistry.Register("test", store)
+	_, good := newNode(t, store, http.StatusOK)
+	_, bad := newNode(t, store, http.StatusInternalServerError)
+
+	client := DefaultContainer().Build(WithAddr("test:///user-svc"), WithEject(2, time.Minute))
+	// 连续失败2次后摘除5xx的节点
+	for i := 0; i < 20; i++ {
+		_, err := client.R().Get("/hello")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{good.Address: 50}, hosts(t, client, 50))
+
+	// watch到新节点后参与负载均衡
+	_, added := newNode(t, store, http.StatusOK)
+	assert.Eventually(t, func() bool {
+		return hosts(t, client, 20)[added.Address] > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// 关闭的节点不再访问，剩下的节点都被摘除时在全部节点中选择
+	good.Enable = false
+	added.Enable = false
+	require.NoError(t, store.RegisterService(context.Background(), good))
+	require.NoError(t, store.RegisterService(context.Background(), added))
+	assert.Eventually(t, func() bool {
+		res, err := client.R().Get("/hello")
+		return err == nil && res.StatusCode() == http.StatusInternalServerError && res.String() == bad.Address
+	}, time.Second, 10*time.Millisecond)
+
+	// 没有节点
+	require.NoError(t, store.UnregisterService(context.Background(), bad))
+	assert.Eventually(t, func() bool {
+		_, err := client.R().Get("/hello")
+		return err != nil && strings.Contains(err.Error(), errNoAvailableNode.Error())
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRegisterConsumer(t *testing.T) {
+	store := ememory.NewStore()
+	defer store.Close()
+	eregistry.Register("consumer", store)
+	defer func() {
+		assert.NoError(t, eregistry.UnregisterConsumers())
+	}()
+
+	DefaultContainer().Build(WithAddr("consumer:///user-svc"), WithEnableRegisterConsumer(true))
+	_, endpoints := store.Snapshot("user-svc", "http")
+	require.Len(t, endpoints.ConsumerConfigs, 1)
+	for _, config := range endpoints.ConsumerConfigs {
+		assert.Equal(t, "http", config.Scheme)
+	}
+}
+
+func TestBalancerKeepsUserHookAndClose(t *testing.T) {
+	store := ememory.NewStore()
+	defer store.Close()
+	eregistry.Register("hook", store)
+	_, first := newNode(t, store, http.StatusOK)
+
+	client := DefaultContainer().Build(WithAddr("hook:///user-svc"))
+	// 用户设置的PreRequestHook不会被负载均衡覆盖
+	called := 0
+	client.SetPreRequestHook(func(_ *resty.Client, _ *http.Request) error {
+		called++
+		return nil
+	})
+	assert.Equal(t, map[string]int{first.Address: 5}, hosts(t, client, 5))
+	assert.Equal(t, 5, called)
+
+	// Close后不再watch新节点
+	require.NoError(t, client.Close())
+	_, added := newNode(t, store, http.StatusOK)
+	assert.Never(t, func() bool {
+		return hosts(t, client, 5)[added.Address] > 0
+	}, 200*time.Millisecond, 20*time.Millisecond)
+}
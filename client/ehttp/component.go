@@ -1,18 +1,20 @@
 package ehttp
 
 import (
+	"context"
 	"log"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
-	"github.com/opentracing/opentracing-go"
 	"golang.org/x/net/publicsuffix"
 
 	"github.com/gotomicro/ego/core/eapp"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
 	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/util/xdebug"
 )
@@ -25,6 +27,7 @@ type Component struct {
 	name   string
 	config *Config
 	logger *elog.Component
+	lb     *balancer
 	*resty.Client
 }
 
@@ -57,7 +60,7 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 		)
 
 		// 开启了链路，那么就记录链路id
-		if config.EnableTraceInterceptor && opentracing.IsGlobalTracerRegistered() {
+		if config.EnableTraceInterceptor && etrace.IsGlobalTracerRegistered() {
 			fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(request.Context())))
 		}
 
@@ -86,46 +89,128 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 		}
 	}
 
+	// 每次请求创建client span，并按配置的传递格式写入请求头，父span中的baggage随之传递
+	var beforeRequestTrace = func(client *resty.Client, request *resty.Request) error {
+		if !config.EnableTraceInterceptor || !etrace.IsGlobalTracerRegistered() {
+			return nil
+		}
+		parent := request.Context()
+		// 重试时，上一次请求的span还未结束，新的span仍挂在原来的父span下
+		if prev, ok := parent.Value(clientSpanKey{}).(*clientSpan); ok {
+			prev.finish(nil, errRetry)
+			parent = prev.parent
+		}
+		fullURL := request.URL
+		if !strings.HasPrefix(fullURL, "http://") && !strings.HasPrefix(fullURL, "https://") {
+			fullURL = strings.TrimRight(client.HostURL, "/") + "/" + strings.TrimLeft(fullURL, "/")
+		}
+		span, ctx := etrace.StartSpanFromContext(
+			parent,
+			metaFromRequest(request).method,
+			etrace.TagComponent("http"),
+			etrace.TagSpanKind("client"),
+			etrace.CustomTag("http.url", fullURL),
+			etrace.CustomTag("http.method", request.Method),
+		)
+		etrace.HeaderInjector(ctx, request.Header)
+		request.SetContext(context.WithValue(ctx, clientSpanKey{}, &clientSpan{span: span, parent: parent}))
+		return nil
+	}
+
+	// Addr为注册中心地址时，通过服务名访问，每次请求时替换为选择的节点
+	var (
+		transport http.RoundTripper = createTransport(name, config)
+		hostURL                     = config.Addr
+		lb        *balancer
+	)
+	if target, ok := parseTarget(config.Addr); ok {
+		var err error
+		if lb, err = newBalancer(target.reg, target.service, config, logger); err != nil {
+			logger.Panic("watch services", elog.FieldName(target.service), elog.FieldErr(err))
+		}
+		transport = &balancerTransport{next: transport, balancer: lb}
+		hostURL = "http://" + target.service
+		// 注册失败时由Lease重试
+		if config.EnableRegisterConsumer {
+			if err := eregistry.RegisterConsumer(context.Background(), target.registry, target.service, "http"); err != nil {
+				logger.Error("register consumer", elog.FieldName(target.service), elog.FieldErr(err))
+			}
+		}
+	}
+
+	// 监控指标的peer，进行中的请求数、连接数、请求数使用相同的值
+	peer := metricPeer(config.Addr)
+
 	// resty的默认方法，无法设置长连接个数，和是否开启长连接，这里重新构造http client。
 	cookieJar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 	restyClient := resty.NewWithClient(&http.Client{
-		Transport: createTransport(config),
+		Transport: transport,
 		Jar:       cookieJar,
 	}).
 		SetDebug(config.RawDebug).
 		SetTimeout(config.ReadTimeout).
 		SetHeader("app", eapp.Name()).
+		OnBeforeRequest(beforeRequestMeta).
+		OnBeforeRequest(beforeRequestTrace).
 		OnAfterResponse(func(client *resty.Client, response *resty.Response) error {
 			logAccess(response.Request, response, nil)
+			if config.EnableMetricInterceptor {
+				metricAccess(name, peer, response.Request, response, nil)
+			}
+			finishClientSpan(response.Request, response, nil)
 			return nil
 		}).
 		OnError(func(req *resty.Request, err error) {
 			if v, ok := err.(*resty.ResponseError); ok {
 				logAccess(req, v.Response, v.Err)
+				if config.EnableMetricInterceptor {
+					metricAccess(name, peer, req, v.Response, v.Err)
+				}
+				finishClientSpan(req, v.Response, v.Err)
 			} else {
 				logAccess(req, nil, err)
+				if config.EnableMetricInterceptor {
+					metricAccess(name, peer, req, nil, err)
+				}
+				finishClientSpan(req, nil, err)
 			}
 		}).
-		SetHostURL(config.Addr)
+		SetHostURL(hostURL)
+	if config.EnableMetricInterceptor {
+		restyClient.OnBeforeRequest(metricStart(name, peer))
+	}
 
 	return &Component{
 		name:   name,
 		config: config,
 		logger: logger,
+		lb:     lb,
 		Client: restyClient,
 	}
 }
 
-func createTransport(config *Config) *http.Transport {
+// Close 停止注册中心的节点watch
+func (c *Component) Close() error {
+	if c.lb != nil {
+		c.lb.close()
+	}
+	return nil
+}
+
+func createTransport(name string, config *Config) *http.Transport {
 	dialer := &net.Dialer{
 		Timeout:   30 * time.Second,
 		KeepAlive: 30 * time.Second,
 		DualStack: true,
 	}
+	dialContext := dialer.DialContext
+	if config.EnableMetricInterceptor {
+		dialContext = (&metricDialer{name: name, peer: metricPeer(config.Addr), next: dialer.DialContext}).DialContext
+	}
 
 	return &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           dialer.DialContext,
+		DialContext:           dialContext,
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          config.MaxIdleConns,
 		IdleConnTimeout:       config.IdleConnTimeout,
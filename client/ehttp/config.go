@@ -9,7 +9,7 @@ import (
 
 // Config HTTP配置选项
 type Config struct {
-	Addr                       string        // 连接地址
+	Addr                       string        // 连接地址，直连为http://127.0.0.1:9001，服务发现为{注册中心}:///{服务名}，注册中心通过eregistry.Register注册
 	Debug                      bool          // 是否开启调试，默认不开启，开启后并加上export EGO_DEBUG=true，可以看到每次请求，配置名、地址、耗时、请求数据、响应数据
 	RawDebug                   bool          // 是否开启原生调试，默认不开启
 	ReadTimeout                time.Duration // 读超时，默认2s
@@ -18,9 +18,13 @@ type Config struct {
 	MaxIdleConns               int           // 设置最大空闲连接数
 	MaxIdleConnsPerHost        int           // 设置长连接个数
 	EnableTraceInterceptor     bool          // 是否开启链路追踪，默认开启
+	EnableMetricInterceptor    bool          // 是否开启监控，默认开启
 	EnableKeepAlives           bool          // 是否开启长连接，默认打开
 	EnableAccessInterceptor    bool          // 是否开启记录请求数据，默认不开启
 	EnableAccessInterceptorRes bool          // 是否开启记录响应参数，默认不开启
+	EjectFailures              int           // 服务发现时，节点连续失败(连接错误或5xx)多少次后暂时摘除，默认3，0不摘除
+	EjectDuration              time.Duration // 服务发现时，节点摘除的时间，默认30s
+	EnableRegisterConsumer     bool          // 服务发现时是否将本应用注册为服务的消费方，默认不开启
 }
 
 // DefaultConfig ...
@@ -35,7 +39,10 @@ func DefaultConfig() *Config {
 		IdleConnTimeout:            90 * time.Second,
 		EnableKeepAlives:           true,
 		EnableTraceInterceptor:     true,
+		EnableMetricInterceptor:    true,
 		EnableAccessInterceptor:    false,
 		EnableAccessInterceptorRes: false,
+		EjectFailures:              3,
+		EjectDuration:              30 * time.Second,
 	}
 }
@@ -0,0 +1,170 @@
+package ehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// requestMetaKey 请求context中存放requestMeta的key
+type requestMetaKey struct{}
+
+// requestMeta 请求的路由模板和本次尝试的开始时间
+type requestMeta struct {
+	method string // GET./users/{id}
+	start  time.Time
+	active bool // 是否计入了进行中的请求数
+}
+
+// route 返回路由模板，例如R().SetPathParams(...).Get("/users/{id}")返回/users/{id}，
+// 需要在resty替换path参数之前调用，请求地址中直接拼接的参数会导致监控指标的label过多
+func route(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	if strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") {
+		rawURL = strings.SplitN(rawURL, "://", 2)[1]
+		if i := strings.Index(rawURL, "/"); i >= 0 {
+			return rawURL[i:]
+		}
+		return "/"
+	}
+	return "/" + strings.TrimLeft(rawURL, "/")
+}
+
+// beforeRequestMeta 记录路由模板和开始时间，重试时沿用第一次记录的路由模板
+func beforeRequestMeta(client *resty.Client, request *resty.Request) error {
+	meta, ok := request.Context().Value(requestMetaKey{}).(*requestMeta)
+	if !ok {
+		meta = &requestMeta{method: request.Method + "." + route(request.URL)}
+		request.SetContext(context.WithValue(request.Context(), requestMetaKey{}, meta))
+	}
+	meta.start = time.Now()
+	return nil
+}
+
+// metaFromRequest ...
+func metaFromRequest(request *resty.Request) *requestMeta {
+	if meta, ok := request.Context().Value(requestMetaKey{}).(*requestMeta); ok {
+		return meta
+	}
+	return &requestMeta{method: request.Method + "." + route(request.URL), start: request.Time}
+}
+
+// metricPeer 监控指标的peer，直连时为地址中的host:port，服务发现时为服务名，与请求中的host一致
+func metricPeer(addr string) string {
+	if target, ok := parseTarget(addr); ok {
+		return target.service
+	}
+	if u, err := url.Parse(addr); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return addr
+}
+
+// metricStart 进行中的请求数加1，重试时上一次请求失败没有经过metricAccess，先减去
+func metricStart(name string, peer string) resty.RequestMiddleware {
+	return func(client *resty.Client, request *resty.Request) error {
+		meta := metaFromRequest(request)
+		if meta.active {
+			emetric.ClientConnGauge.Add(-1, emetric.TypeHTTP, name, peer, "active")
+		}
+		meta.active = true
+		emetric.ClientConnGauge.Inc(emetric.TypeHTTP, name, peer, "active")
+		return nil
+	}
+}
+
+// metricAccess 记录请求数、耗时，code为状态码分类(2xx、4xx、5xx)，没有响应时为错误类型
+func metricAccess(name string, peer string, request *resty.Request, response *resty.Response, err error) {
+	meta := metaFromRequest(request)
+	if meta.active {
+		meta.active = false
+		emetric.ClientConnGauge.Add(-1, emetric.TypeHTTP, name, peer, "active")
+	}
+	// 请求地址为完整url时，peer为请求中的host
+	if request.RawRequest != nil {
+		peer = request.RawRequest.URL.Host
+	}
+	code := "unknown"
+	if response != nil && response.RawResponse != nil {
+		code = statusClass(response.StatusCode())
+	} else if err != nil {
+		code = errorKind(err)
+	}
+	emetric.ClientHandleCounter.Inc(emetric.TypeHTTP, name, meta.method, peer, code)
+	emetric.ClientHandleHistogram.ObserveWithTrace(time.Since(meta.start).Seconds(), etrace.ExtractTraceID(request.Context()), emetric.TypeHTTP, name, meta.method, peer)
+}
+
+// statusClass 状态码分类
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// errorKind 没有响应时的错误类型
+func errorKind(err error) string {
+	var netErr net.Error
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, new(*net.DNSError)):
+		return "dns"
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return "dial"
+	case errors.As(err, new(*url.Error)):
+		return "transport"
+	default:
+		return "unknown"
+	}
+}
+
+// metricDialer 记录连接池中打开的连接数，peer与进行中的请求数相同，不使用拨号的地址
+type metricDialer struct {
+	name string
+	peer string
+	next func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// DialContext ...
+func (d *metricDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.next(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	emetric.ClientConnGauge.Inc(emetric.TypeHTTP, d.name, d.peer, "open")
+	return &metricConn{Conn: conn, name: d.name, peer: d.peer}, nil
+}
+
+// metricConn 关闭时减少打开的连接数
+type metricConn struct {
+	net.Conn
+	name string
+	peer string
+	once sync.Once
+}
+
+// Close ...
+func (c *metricConn) Close() error {
+	c.once.Do(func() {
+		emetric.ClientConnGauge.Add(-1, emetric.TypeHTTP, c.name, c.peer, "open")
+	})
+	return c.Conn.Close()
+}
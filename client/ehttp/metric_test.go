@@ -0,0 +1,69 @@
+package ehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/eregistry/ememory"
+)
+
+func TestRoute(t *testing.T) {
+	assert.Equal(t, "/users/{id}", route("/users/{id}?page=1"))
+	assert.Equal(t, "/users/{id}", route("users/{id}"))
+	assert.Equal(t, "/hello", route("http://127.0.0.1:8080/hello#top"))
+	assert.Equal(t, "/", route("https://127.0.0.1:8080"))
+}
+
+func TestMetricPeer(t *testing.T) {
+	eregistry.Register("peer", ememory.NewStore())
+	assert.Equal(t, "127.0.0.1:9001", metricPeer("http://127.0.0.1:9001"))
+	assert.Equal(t, "user-svc", metricPeer("peer:///user-svc"))
+}
+
+func TestErrorKind(t *testing.T) {
+	assert.Equal(t, "timeout", errorKind(&url.Error{Op: "Get", Err: context.DeadlineExceeded}))
+	assert.Equal(t, "canceled", errorKind(context.Canceled))
+	assert.Equal(t, "dial", errorKind(&url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}))
+	assert.Equal(t, "dns", errorKind(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host"}}))
+	assert.Equal(t, "unknown", errorKind(errors.New("x")))
+	assert.Equal(t, "2xx", statusClass(http.StatusOK))
+	assert.Equal(t, "5xx", statusClass(http.StatusBadGateway))
+}
+
+func TestClientMetric(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/404" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := DefaultContainer().Build(WithAddr(server.URL))
+	peer := server.Listener.Addr().String()
+	for _, id := range []string{"1", "2", "404"} {
+		_, err := client.R().SetPathParams(map[string]string{"id": id}).Get("/users/{id}")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, float64(2), testutil.ToFloat64(emetric.ClientHandleCounter.WithLabelValues(emetric.TypeHTTP, "", "GET./users/{id}", peer, "2xx")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.ClientHandleCounter.WithLabelValues(emetric.TypeHTTP, "", "GET./users/{id}", peer, "4xx")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(emetric.ClientConnGauge.WithLabelValues(emetric.TypeHTTP, "", peer, "active")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.ClientConnGauge.WithLabelValues(emetric.TypeHTTP, "", peer, "open")))
+
+	// 连接失败
+	closed := DefaultContainer().Build(WithAddr("http://127.0.0.1:1"), WithReadTimeout(time.Second))
+	_, err := closed.R().Get("/hello")
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.ClientHandleCounter.WithLabelValues(emetric.TypeHTTP, "", "GET./hello", "127.0.0.1:1", "dial")))
+}
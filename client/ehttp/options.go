@@ -51,6 +51,13 @@ func WithEnableAccessInterceptorRes(enableAccessInterceptorRes bool) Option {
 	}
 }
 
+// WithEnableMetricInterceptor 设置是否开启监控
+func WithEnableMetricInterceptor(enableMetricInterceptor bool) Option {
+	return func(c *Container) {
+		c.config.EnableMetricInterceptor = enableMetricInterceptor
+	}
+}
+
 // WithMaxIdleConns 设置最大空闲连接数
 func WithMaxIdleConns(maxIdleConns int) Option {
 	return func(c *Container) {
@@ -71,3 +78,18 @@ func WithEnableKeepAlives(enableKeepAlives bool) Option {
 		c.config.EnableKeepAlives = enableKeepAlives
 	}
 }
+
+// WithEject 设置服务发现时节点连续失败多少次后摘除，以及摘除的时间
+func WithEject(failures int, duration time.Duration) Option {
+	return func(c *Container) {
+		c.config.EjectFailures = failures
+		c.config.EjectDuration = duration
+	}
+}
+
+// WithEnableRegisterConsumer 设置服务发现时是否注册为服务的消费方
+func WithEnableRegisterConsumer(enableRegisterConsumer bool) Option {
+	return func(c *Container) {
+		c.config.EnableRegisterConsumer = enableRegisterConsumer
+	}
+}
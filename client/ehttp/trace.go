@@ -0,0 +1,53 @@
+package ehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/log"
+)
+
+// errRetry 请求被重试时，结束上一次请求的span
+var errRetry = errors.New("request retried")
+
+// clientSpanKey 请求context中存放clientSpan的key
+type clientSpanKey struct{}
+
+// clientSpan 一次请求的client span
+type clientSpan struct {
+	span   opentracing.Span
+	parent context.Context
+	once   sync.Once
+}
+
+// finish 记录状态码、错误并结束span，重复调用只生效一次
+func (s *clientSpan) finish(response *resty.Response, err error) {
+	s.once.Do(func() {
+		if response != nil && response.RawResponse != nil {
+			ext.HTTPStatusCode.Set(s.span, uint16(response.StatusCode()))
+			if response.StatusCode() >= http.StatusBadRequest && err == nil {
+				err = errors.New(response.Status())
+			}
+		}
+		if err != nil {
+			ext.Error.Set(s.span, true)
+			s.span.LogFields(log.String("event", "error"), log.String("message", err.Error()))
+		}
+		s.span.Finish()
+	})
+}
+
+// finishClientSpan 结束请求context中的client span
+func finishClientSpan(request *resty.Request, response *resty.Response, err error) {
+	if request == nil {
+		return
+	}
+	if s, ok := request.Context().Value(clientSpanKey{}).(*clientSpan); ok {
+		s.finish(response, err)
+	}
+}
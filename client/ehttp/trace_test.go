@@ -0,0 +1,92 @@
+package ehttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientTrace(t *testing.T) {
+	tracer := mocktracer.New()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := DefaultContainer().Build(WithAddr(server.URL))
+	parent := tracer.StartSpan("parent")
+	parent.SetBaggageItem("uid", "100")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+
+	_, err := client.R().SetContext(ctx).Get("/hello")
+	require.NoError(t, err)
+	_, err = client.R().SetContext(ctx).Get("/fail")
+	require.NoError(t, err)
+	_, err = client.R().SetContext(ctx).SetPathParams(map[string]string{"id": "1"}).Get("/users/{id}")
+	require.NoError(t, err)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 3)
+	ok := spans[0]
+	assert.Equal(t, "GET./hello", ok.OperationName)
+	assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, ok.ParentID)
+	assert.Equal(t, "client", ok.Tag("span.kind"))
+	assert.Equal(t, server.URL+"/hello", ok.Tag("http.url"))
+	assert.Equal(t, "GET", ok.Tag("http.method"))
+	assert.Equal(t, uint16(http.StatusOK), ok.Tag("http.status_code"))
+	assert.Nil(t, ok.Tag("error"))
+
+	fail := spans[1]
+	assert.Equal(t, "GET./fail", fail.OperationName)
+	assert.Equal(t, uint16(http.StatusInternalServerError), fail.Tag("http.status_code"))
+	assert.Equal(t, true, fail.Tag("error"))
+	// span名称使用路由模板，不带具体的path参数
+	assert.Equal(t, "GET./users/{id}", spans[2].OperationName)
+
+	// 请求头中带上了client span和父span的baggage
+	assert.Equal(t, "100", header.Get("Mockpfx-Baggage-Uid"))
+	assert.Equal(t, "true", header.Get("Mockpfx-Ids-Sampled"))
+}
+
+func TestClientTraceRetry(t *testing.T) {
+	tracer := mocktracer.New()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := DefaultContainer().Build(WithAddr(server.URL))
+	client.SetRetryCount(2).AddRetryCondition(func(response *resty.Response, err error) bool {
+		return response.StatusCode() == http.StatusServiceUnavailable
+	})
+	parent := tracer.StartSpan("parent")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+	_, err := client.R().SetContext(ctx).SetPathParams(map[string]string{"id": "1"}).Get("/retry/{id}")
+	require.NoError(t, err)
+
+	// 每次重试一个span，都挂在同一个父span下
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 3)
+	for _, span := range spans {
+		assert.Equal(t, "GET./retry/{id}", span.OperationName)
+		assert.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, span.ParentID)
+		assert.Equal(t, true, span.Tag("error"))
+	}
+}
@@ -134,3 +134,24 @@ func WithFallbackCore(core zapcore.Core) Option {
 		c.fallbackCore = core
 	}
 }
+
+// WithSpoolDir 设置磁盘队列目录，为空则不开启磁盘队列
+func WithSpoolDir(spoolDir string) Option {
+	return func(c *config) {
+		c.spoolDir = spoolDir
+	}
+}
+
+// WithSpoolMaxSize 设置磁盘队列最大字节数，超过后写入fallback logger
+func WithSpoolMaxSize(spoolMaxSize int64) Option {
+	return func(c *config) {
+		c.spoolMaxSize = spoolMaxSize
+	}
+}
+
+// WithSpoolReplayInterval 设置磁盘队列重放间隔
+func WithSpoolReplayInterval(spoolReplayInterval time.Duration) Option {
+	return func(c *config) {
+		c.spoolReplayInterval = spoolReplayInterval
+	}
+}
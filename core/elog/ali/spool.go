@@ -0,0 +1,166 @@
+package ali
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/gotomicro/ego/core/elog/ali/pb"
+)
+
+const (
+	// spoolFileExt 磁盘队列文件后缀
+	spoolFileExt = ".spool"
+	// spoolTmpExt 写入中的临时文件后缀，启动时会被清理
+	spoolTmpExt = ".tmp"
+)
+
+// errSpoolFull 磁盘队列已满
+var errSpoolFull = errors.New("ali spool is full")
+
+type spoolFile struct {
+	name string
+	size int64
+}
+
+// spool is a durable on-disk FIFO of log groups.
+// Every failed batch is stored as one file named by a monotonically increasing sequence,
+// so replay order equals push order, including across restarts.
+type spool struct {
+	mu      sync.Mutex
+	dir     string
+	maxSize int64
+	seq     uint64
+	size    int64
+	files   []spoolFile
+}
+
+// newSpool opens the spool directory and loads the files left by previous runs
+func newSpool(dir string, maxSize int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create spool dir fail, %w", err)
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read spool dir fail, %w", err)
+	}
+	s := &spool{
+		dir:     dir,
+		maxSize: maxSize,
+		files:   make([]spoolFile, 0),
+	}
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(name, spoolTmpExt) {
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, spoolFileExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		if seq > s.seq {
+			s.seq = seq
+		}
+		s.files = append(s.files, spoolFile{name: name, size: info.Size()})
+		s.size += info.Size()
+	}
+	// 文件名为定长序号，字典序即为写入顺序
+	sort.Slice(s.files, func(i, j int) bool {
+		return s.files[i].name < s.files[j].name
+	})
+	return s, nil
+}
+
+// push appends a log group to the tail of the spool
+func (s *spool) push(lg *pb.LogGroup) error {
+	body, err := proto.Marshal(lg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxSize > 0 && s.size+int64(len(body)) > s.maxSize {
+		return errSpoolFull
+	}
+	s.seq++
+	name := fmt.Sprintf("%020d%s", s.seq, spoolFileExt)
+	path := filepath.Join(s.dir, name)
+	// 先写临时文件再rename，避免进程退出时留下半个文件
+	if err := ioutil.WriteFile(path+spoolTmpExt, body, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(path+spoolTmpExt, path); err != nil {
+		return err
+	}
+	s.files = append(s.files, spoolFile{name: name, size: int64(len(body))})
+	s.size += int64(len(body))
+	return nil
+}
+
+// peek returns the head of the spool without removing it.
+// A corrupted head file is discarded and the next one is returned.
+func (s *spool) peek() (*pb.LogGroup, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.files) > 0 {
+		head := s.files[0]
+		body, err := ioutil.ReadFile(filepath.Join(s.dir, head.name))
+		if err == nil {
+			lg := &pb.LogGroup{}
+			if err = proto.Unmarshal(body, lg); err == nil {
+				return lg, head.name, nil
+			}
+		}
+		s.removeHead()
+		if !os.IsNotExist(err) {
+			return nil, "", fmt.Errorf("discard corrupted spool file %s, %w", head.name, err)
+		}
+	}
+	return nil, "", nil
+}
+
+// remove deletes the head of the spool if it is still the given file
+func (s *spool) remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.files) > 0 && s.files[0].name == name {
+		s.removeHead()
+	}
+}
+
+func (s *spool) removeHead() {
+	head := s.files[0]
+	_ = os.Remove(filepath.Join(s.dir, head.name))
+	s.files = s.files[1:]
+	s.size -= head.size
+}
+
+// depth returns the number of spooled log groups
+func (s *spool) depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files)
+}
+
+// bytes returns the disk usage of the spool
+func (s *spool) bytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
@@ -0,0 +1,83 @@
+package ali
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/elog/ali/pb"
+)
+
+func newLogGroup(msg string) *pb.LogGroup {
+	return &pb.LogGroup{Logs: []*pb.Log{genLog(map[string]interface{}{"msg": msg})}}
+}
+
+func logGroupMsg(lg *pb.LogGroup) string {
+	return lg.Logs[0].Contents[0].GetValue()
+}
+
+func TestSpoolOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ali-spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := newSpool(dir, 0)
+	assert.NoError(t, err)
+	for _, msg := range []string{"1", "2", "3"} {
+		assert.NoError(t, s.push(newLogGroup(msg)))
+	}
+	assert.Equal(t, 3, s.depth())
+
+	// 重新打开，模拟进程重启
+	s, err = newSpool(dir, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, s.depth())
+	assert.NoError(t, s.push(newLogGroup("4")))
+
+	for _, want := range []string{"1", "2", "3", "4"} {
+		lg, name, err := s.peek()
+		assert.NoError(t, err)
+		assert.Equal(t, want, logGroupMsg(lg))
+		s.remove(name)
+	}
+	lg, _, err := s.peek()
+	assert.NoError(t, err)
+	assert.Nil(t, lg)
+	assert.Equal(t, int64(0), s.bytes())
+}
+
+func TestSpoolMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ali-spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	body, _ := proto.Marshal(newLogGroup("1"))
+	s, err := newSpool(dir, int64(len(body))*2)
+	assert.NoError(t, err)
+	assert.NoError(t, s.push(newLogGroup("1")))
+	assert.NoError(t, s.push(newLogGroup("2")))
+	assert.Equal(t, errSpoolFull, s.push(newLogGroup("3")))
+	assert.Equal(t, 2, s.depth())
+}
+
+func TestSpoolDiscardCorrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ali-spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := newSpool(dir, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, s.push(newLogGroup("1")))
+	assert.NoError(t, s.push(newLogGroup("2")))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, s.files[0].name), []byte("corrupted"), 0644))
+
+	_, _, err = s.peek()
+	assert.Error(t, err)
+	lg, _, err := s.peek()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", logGroupMsg(lg))
+}
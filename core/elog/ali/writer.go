@@ -31,6 +31,8 @@ const (
 	observeInterval = 5 * time.Second
 	// apiBulkMinSize sets bulk minimal size
 	apiBulkMinSize = 256
+	// defaultSpoolReplayInterval 磁盘队列默认重放间隔
+	defaultSpoolReplayInterval = 5 * time.Second
 )
 
 // LogContent ...
@@ -57,6 +59,9 @@ type config struct {
 	apiIdleConnTimeout     time.Duration
 	apiMaxIdleConnsPerHost int
 	fallbackCore           zapcore.Core
+	spoolDir               string
+	spoolMaxSize           int64
+	spoolReplayInterval    time.Duration
 }
 
 // writer implements LoggerInterface.
@@ -66,8 +71,11 @@ type writer struct {
 	store        *LogStore
 	ch           chan *pb.Log
 	lock         sync.Mutex
+	spoolLock    sync.Mutex // 串行化发送与重放，保证磁盘队列里的日志有序
 	curBufSize   *int32
+	ctx          context.Context
 	cancel       context.CancelFunc
+	spool        *spool
 	config
 }
 
@@ -114,6 +122,16 @@ func newWriter(c config) (*writer, error) {
 	}
 	w.store = store
 	w.fallbackCore = c.fallbackCore
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	if c.spoolDir != "" {
+		if w.spool, err = newSpool(c.spoolDir, c.spoolMaxSize); err != nil {
+			return nil, err
+		}
+		if w.spoolReplayInterval <= 0 {
+			w.spoolReplayInterval = defaultSpoolReplayInterval
+		}
+		w.replay()
+	}
 	w.sync()
 	w.observe()
 	return w, nil
@@ -180,27 +198,103 @@ func (w *writer) flush() error {
 	w.lock.Unlock()
 
 	chunks := int(math.Ceil(float64(len(waitedEntries)) / float64(w.apiBulkSize)))
+	groups := make([]pb.LogGroup, chunks)
+	for i := 0; i < chunks; i++ {
+		end := (i + 1) * w.apiBulkSize
+		if end > len(waitedEntries) {
+			end = len(waitedEntries)
+		}
+		groups[i] = pb.LogGroup{Logs: waitedEntries[i*w.apiBulkSize : end]}
+	}
+
+	if w.spool != nil {
+		w.flushWithSpool(groups)
+		return nil
+	}
+
+	failed := make([]bool, chunks)
 	wg := sync.WaitGroup{}
 	wg.Add(chunks)
 	for i := 0; i < chunks; i++ {
-		go func(start int) {
-			end := (start + 1) * w.apiBulkSize
-			if end > len(waitedEntries) {
-				end = len(waitedEntries)
-			}
-			lg := pb.LogGroup{Logs: waitedEntries[start:end]}
-			if e := w.store.PutLogs(&lg); e != nil {
-				// if error occurs we put logs to fallback logger
-				w.writeToFallbackLogger(lg)
+		go func(i int) {
+			if e := w.store.PutLogs(&groups[i]); e != nil {
+				failed[i] = true
 			}
 			wg.Done()
 		}(i)
 	}
 	wg.Wait()
 
+	for i := range groups {
+		// if error occurs we put logs to fallback logger
+		if failed[i] {
+			w.writeToFallbackLogger(groups[i])
+		}
+	}
 	return nil
 }
 
+// flushWithSpool sends the log groups one by one, the first failed group and all after it go to the spool
+func (w *writer) flushWithSpool(groups []pb.LogGroup) {
+	w.spoolLock.Lock()
+	defer w.spoolLock.Unlock()
+	// 磁盘队列里还有未重放的日志，为了保证顺序，新的日志直接排到队尾
+	failed := w.spool.depth() > 0
+	for i := range groups {
+		if !failed && w.store.PutLogs(&groups[i]) == nil {
+			continue
+		}
+		failed = true
+		w.writeToSpool(groups[i])
+	}
+}
+
+// writeToSpool persists the log group, falls back to the fallback logger if the spool is full
+func (w *writer) writeToSpool(lg pb.LogGroup) {
+	if err := w.spool.push(&lg); err != nil {
+		emetric.LibHandleCounter.Inc("elog", "ali_spool", w.endpoint, "drop")
+		w.writeToFallbackLogger(lg)
+		return
+	}
+	emetric.LibHandleCounter.Inc("elog", "ali_spool", w.endpoint, "push")
+}
+
+// replay sends the spooled log groups in order, and stops at the first failure until next round
+func (w *writer) replay() {
+	ticker := time.NewTicker(w.spoolReplayInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.replaySpool()
+			case <-w.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (w *writer) replaySpool() {
+	w.spoolLock.Lock()
+	defer w.spoolLock.Unlock()
+	for {
+		lg, name, err := w.spool.peek()
+		if err != nil {
+			log.Println("spool peek fail", err)
+			continue
+		}
+		if lg == nil {
+			return
+		}
+		if err = w.store.PutLogs(lg); err != nil {
+			return
+		}
+		w.spool.remove(name)
+		emetric.LibHandleCounter.Inc("elog", "ali_spool", w.endpoint, "replay")
+	}
+}
+
 func (w *writer) writeToFallbackLogger(lg pb.LogGroup) {
 	for _, v := range lg.Logs {
 		fields := make([]zapcore.Field, len(v.Contents))
@@ -214,8 +308,6 @@ func (w *writer) writeToFallbackLogger(lg pb.LogGroup) {
 }
 
 func (w *writer) sync() {
-	ctx, cancel := context.WithCancel(context.Background())
-	w.cancel = cancel
 	ticker := time.NewTicker(w.flushBufferInterval)
 	go func() {
 		defer ticker.Stop()
@@ -225,7 +317,7 @@ func (w *writer) sync() {
 				if err := w.flush(); err != nil {
 					log.Printf("writer flush fail, %s\n", err)
 				}
-			case <-ctx.Done():
+			case <-w.ctx.Done():
 				return
 			}
 		}
@@ -233,10 +325,20 @@ func (w *writer) sync() {
 }
 
 func (w *writer) observe() {
+	ticker := time.NewTicker(observeInterval)
 	go func() {
+		defer ticker.Stop()
 		for {
 			emetric.LibHandleSummary.Observe(float64(len(w.ch)), "elog", "ali_waited_entries")
-			time.Sleep(observeInterval)
+			if w.spool != nil {
+				emetric.LibHandleSummary.Observe(float64(w.spool.depth()), "elog", "ali_spool_depth")
+				emetric.LibHandleSummary.Observe(float64(w.spool.bytes()), "elog", "ali_spool_bytes")
+			}
+			select {
+			case <-ticker.C:
+			case <-w.ctx.Done():
+				return
+			}
 		}
 	}()
 }
@@ -0,0 +1,153 @@
+package ali
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pierrec/lz4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/elog/ali/pb"
+)
+
+func TestWriterSpoolReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ali-spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		available int32
+		mu        sync.Mutex
+		received  []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"logstoreName":"test"}`))
+			return
+		}
+		if atomic.LoadInt32(&available) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errorCode":"Unavailable","errorMessage":"unavailable"}`))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		rawSize, _ := strconv.Atoi(r.Header.Get("x-log-bodyrawsize"))
+		raw := make([]byte, rawSize)
+		n, err := lz4.UncompressBlock(body, raw)
+		assert.NoError(t, err)
+		lg := &pb.LogGroup{}
+		assert.NoError(t, proto.Unmarshal(raw[:n], lg))
+		mu.Lock()
+		for _, l := range lg.Logs {
+			received = append(received, l.Contents[0].GetValue())
+		}
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	w, err := newWriter(config{
+		endpoint:            ts.URL,
+		logstore:            "test",
+		flushBufferInterval: time.Hour,
+		apiTimeout:          time.Second,
+		spoolDir:            dir,
+		spoolReplayInterval: 20 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	defer w.cancel()
+
+	// 接口不可用，日志进入磁盘队列
+	assert.NoError(t, w.write(map[string]interface{}{"msg": "1"}))
+	assert.NoError(t, w.flush())
+	assert.Equal(t, 1, w.spool.depth())
+	// 磁盘队列不为空，新日志排在队尾
+	assert.NoError(t, w.write(map[string]interface{}{"msg": "2"}))
+	assert.NoError(t, w.flush())
+	assert.Equal(t, 2, w.spool.depth())
+
+	// 接口恢复，按顺序重放
+	atomic.StoreInt32(&available, 1)
+	assert.Eventually(t, func() bool {
+		return w.spool.depth() == 0
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, w.write(map[string]interface{}{"msg": "3"}))
+	assert.NoError(t, w.flush())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"1", "2", "3"}, received)
+}
+
+func TestWriterSpoolKeepsChunkOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ali-spool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		posts    int32
+		mu       sync.Mutex
+		received []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"logstoreName":"test"}`))
+			return
+		}
+		// 第二个分块发送失败
+		if atomic.AddInt32(&posts, 1) == 2 {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errorCode":"Unavailable","errorMessage":"unavailable"}`))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		rawSize, _ := strconv.Atoi(r.Header.Get("x-log-bodyrawsize"))
+		raw := make([]byte, rawSize)
+		n, err := lz4.UncompressBlock(body, raw)
+		assert.NoError(t, err)
+		lg := &pb.LogGroup{}
+		assert.NoError(t, proto.Unmarshal(raw[:n], lg))
+		mu.Lock()
+		for _, l := range lg.Logs {
+			received = append(received, l.Contents[0].GetValue())
+		}
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	w, err := newWriter(config{
+		endpoint:            ts.URL,
+		logstore:            "test",
+		flushBufferSize:     1 << 30,
+		flushBufferInterval: time.Hour,
+		apiTimeout:          time.Second,
+		apiBulkSize:         apiBulkMinSize,
+		spoolDir:            dir,
+		spoolReplayInterval: 20 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	defer w.cancel()
+
+	var expected []string
+	for i := 0; i < 3*apiBulkMinSize; i++ {
+		expected = append(expected, strconv.Itoa(i))
+		assert.NoError(t, w.write(map[string]interface{}{"msg": strconv.Itoa(i)}))
+	}
+	assert.NoError(t, w.flush())
+	// 第一个失败的分块以及之后的分块都进入磁盘队列，重放后保持原有顺序
+	assert.Eventually(t, func() bool {
+		return w.spool.depth() == 0
+	}, 3*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, expected, received)
+}
@@ -11,8 +11,11 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/gotomicro/ego/core/eapp"
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/core/elog/ali"
+	"github.com/gotomicro/ego/core/elog/loki"
+	"github.com/gotomicro/ego/core/elog/syslog"
 	"github.com/gotomicro/ego/core/util/xcolor"
 )
 
@@ -82,7 +85,9 @@ var (
 )
 
 const (
-	defaultAliFallbackCorePath = "ali.log"
+	defaultAliFallbackCorePath    = "ali.log"
+	defaultSyslogFallbackCorePath = "syslog.log"
+	defaultLokiFallbackCorePath   = "loki.log"
 )
 
 // newStderrCore constructs a zapcore.Core with stderr syncer
@@ -93,7 +98,7 @@ func newStderrCore(config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc)
 	if config.EnableAsync {
 		ws, cf = Buffer(ws, config.FlushBufferSize, config.FlushBufferInterval)
 	}
-	core := zapcore.NewCore(zapcore.NewJSONEncoder(*config.encoderConfig), ws, lv)
+	core := zapcore.NewCore(config.newEncoder(), ws, lv)
 	return core, cf
 }
 
@@ -113,7 +118,7 @@ func newRotateFileCore(config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseF
 			if config.Debug {
 				return zapcore.NewConsoleEncoder(*config.encoderConfig)
 			}
-			return zapcore.NewJSONEncoder(*config.encoderConfig)
+			return config.newEncoder()
 		}(),
 		ws,
 		lv,
@@ -145,6 +150,9 @@ func newAliCore(config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
 		ali.WithAPIIdleConnTimeout(config.AliAPIIdleConnTimeout),
 		ali.WithAPIMaxIdleConnsPerHost(config.AliAPIMaxIdleConnsPerHost),
 		ali.WithFallbackCore(fallbackCore),
+		ali.WithSpoolDir(config.AliSpoolDir),
+		ali.WithSpoolMaxSize(int64(config.AliSpoolMaxSize)*1024*1024),
+		ali.WithSpoolReplayInterval(config.AliSpoolReplayInterval),
 	)
 	return core, func() (err error) {
 		if e := cf(); e != nil {
@@ -157,17 +165,83 @@ func newAliCore(config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
 	}
 }
 
-func newCore(config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
-	switch config.Writer {
-	case writerRotateFile:
-		return newRotateFileCore(config, lv)
-	case writerAliSLS:
-		return newAliCore(config, lv)
-	case writerStderr:
-		return newStderrCore(config, lv)
-	default:
-		panic("unsupported writer")
+// newSyslogCore construct a RFC 5424 syslog zapcore.Core
+func newSyslogCore(config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	c := *config
+	c.Name = defaultSyslogFallbackCorePath
+	fallbackCore, fallbackCoreCf := newRotateFileCore(&c, lv)
+	core, cf := syslog.NewCore(
+		syslog.WithEncoder(config.newEncoder()),
+		syslog.WithNetwork(config.SyslogNetwork),
+		syslog.WithAddr(config.SyslogAddr),
+		syslog.WithFacility(config.SyslogFacility),
+		syslog.WithAppName(config.SyslogAppName),
+		syslog.WithTimeout(config.SyslogTimeout),
+		syslog.WithLevelEnabler(lv),
+		syslog.WithFlushBufferSize(config.FlushBufferSize),
+		syslog.WithFlushBufferInterval(config.FlushBufferInterval),
+		syslog.WithFallbackCore(fallbackCore),
+	)
+	return core, func() (err error) {
+		if e := cf(); e != nil {
+			err = fmt.Errorf("exec close func fail, %w ", e)
+		}
+		if e := fallbackCoreCf(); e != nil {
+			err = fmt.Errorf("exec fallbackCore close func fail, %w", e)
+		}
+		return
+	}
+}
+
+// newLokiCore construct a Loki push API zapcore.Core
+func newLokiCore(config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	c := *config
+	c.Name = defaultLokiFallbackCorePath
+	fallbackCore, fallbackCoreCf := newRotateFileCore(&c, lv)
+	labels := make(map[string]string, len(config.LokiLabels)+1)
+	labels["app"] = eapp.Name()
+	for k, v := range config.LokiLabels {
+		labels[k] = v
+	}
+	core, cf := loki.NewCore(
+		loki.WithEncoder(config.newEncoder()),
+		loki.WithURL(config.LokiURL),
+		loki.WithLabels(labels),
+		loki.WithTenantID(config.LokiTenantID),
+		loki.WithLevelEnabler(lv),
+		loki.WithFlushBufferSize(config.FlushBufferSize),
+		loki.WithFlushBufferInterval(config.FlushBufferInterval),
+		loki.WithAPIBulkSize(config.LokiAPIBulkSize),
+		loki.WithAPITimeout(config.LokiAPITimeout),
+		loki.WithAPIRetryCount(config.LokiAPIRetryCount),
+		loki.WithAPIRetryWaitTime(config.LokiAPIRetryWaitTime),
+		loki.WithAPIRetryMaxWaitTime(config.LokiAPIRetryMaxWaitTime),
+		loki.WithFallbackCore(fallbackCore),
+	)
+	return core, func() (err error) {
+		if e := cf(); e != nil {
+			err = fmt.Errorf("exec close func fail, %w ", e)
+		}
+		if e := fallbackCoreCf(); e != nil {
+			err = fmt.Errorf("exec fallbackCore close func fail, %w", e)
+		}
+		return
+	}
+}
+
+func newCore(key string, config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	if config.core != nil {
+		// 自定义core的级别比lv高时，NewIncreaseLevelCore会报错，此时直接使用自定义core
+		if core, err := zapcore.NewIncreaseLevelCore(config.core, lv); err == nil {
+			return core, noopCloseFunc
+		}
+		return config.core, noopCloseFunc
+	}
+	builder := WriterProvider(config.Writer)
+	if builder == nil {
+		panic("unsupported writer: " + config.Writer)
 	}
+	return builder.Build(key, config, lv)
 }
 
 func newLogger(name string, config *Config) *Component {
@@ -184,7 +258,7 @@ func newLogger(name string, config *Config) *Component {
 	if err := lv.UnmarshalText([]byte(config.Level)); err != nil {
 		panic(err)
 	}
-	core, asyncStopFunc := newCore(config, lv)
+	core, asyncStopFunc := newCore(name, config, lv)
 	zapLogger := zap.New(core, zapOptions...)
 	return &Component{
 		desugar:       zapLogger,
@@ -12,42 +12,73 @@ import (
 
 // Config ...
 type Config struct {
-	Debug                     bool          // 是否双写至文件控制日志输出到终端
-	Level                     string        // 日志初始等级，默认info级别
-	Dir                       string        // [fileWriter]日志输出目录，默认logs
-	Name                      string        // [fileWriter]日志文件名称，默认框架日志ego.sys，业务日志default.log
-	MaxSize                   int           // [fileWriter]日志输出文件最大长度，超过改值则截断，默认500M
-	MaxAge                    int           // [fileWriter]日志存储最大时间，默认最大保存天数为7天
-	MaxBackup                 int           // [fileWriter]日志存储最大数量，默认最大保存文件个数为10个
-	RotateInterval            time.Duration // [fileWriter]日志轮转时间，默认1天
-	EnableAddCaller           bool          // 是否添加调用者信息，默认不加调用者信息
-	EnableAsync               bool          // 是否异步，默认异步
-	FlushBufferSize           int           // 缓冲大小，默认256 * 1024B
-	FlushBufferInterval       time.Duration // 缓冲时间，默认5秒
-	Writer                    string        // 使用哪种Writer，可选[file|ali|stderr]，默认file
-	AliAccessKeyID            string        // [aliWriter]阿里云sls AKID，必填
-	AliAccessKeySecret        string        // [aliWriter]阿里云sls AKSecret，必填
-	AliEndpoint               string        // [aliWriter]阿里云sls endpoint，必填
-	AliProject                string        // [aliWriter]阿里云sls Project名称，必填
-	AliLogstore               string        // [aliWriter]阿里云sls logstore名称，必填
-	AliAPIBulkSize            int           // [aliWriter]阿里云sls API单次请求发送最大日志条数，最少256条，默认256条
-	AliAPITimeout             time.Duration // [aliWriter]阿里云sls API接口超时，默认3秒
-	AliAPIRetryCount          int           // [aliWriter]阿里云sls API接口重试次数，默认3次
-	AliAPIRetryWaitTime       time.Duration // [aliWriter]阿里云sls API接口重试默认等待间隔，默认1秒
-	AliAPIRetryMaxWaitTime    time.Duration // [aliWriter]阿里云sls API接口重试最大等待间隔，默认3秒
-	AliAPIMaxIdleConnsPerHost int           // [aliWriter]阿里云sls 单个Host HTTP最大空闲连接数，应当大于AliApiMaxIdleConns
-	AliAPIMaxIdleConns        int           // [aliWriter]阿里云sls HTTP最大空闲连接数
-	AliAPIIdleConnTimeout     time.Duration // [aliWriter]阿里云sls HTTP空闲连接保活时间
+	Debug                     bool              // 是否双写至文件控制日志输出到终端
+	Level                     string            // 日志初始等级，默认info级别
+	Dir                       string            // [fileWriter]日志输出目录，默认logs
+	Name                      string            // [fileWriter]日志文件名称，默认框架日志ego.sys，业务日志default.log
+	MaxSize                   int               // [fileWriter]日志输出文件最大长度，超过改值则截断，默认500M
+	MaxAge                    int               // [fileWriter]日志存储最大时间，默认最大保存天数为7天
+	MaxBackup                 int               // [fileWriter]日志存储最大数量，默认最大保存文件个数为10个
+	RotateInterval            time.Duration     // [fileWriter]日志轮转时间，默认1天
+	EnableCompress            bool              // [fileWriter]是否gzip压缩轮转后的日志，默认不压缩
+	MaxTotalSize              int               // [fileWriter]日志目录下所有日志及备份的最大总大小，超过后删除目录中最旧的备份，单位M，默认0不限制
+	Symlink                   string            // [fileWriter]指向当前日志文件的软链接名称，相对路径基于Dir，默认不创建
+	EnableAddCaller           bool              // 是否添加调用者信息，默认不加调用者信息
+	EnableAsync               bool              // 是否异步，默认异步
+	FlushBufferSize           int               // 缓冲大小，默认256 * 1024B
+	FlushBufferInterval       time.Duration     // 缓冲时间，默认5秒
+	Encoder                   string            // 日志编码格式，可选[json|console|logfmt]，默认json，调试模式下fileWriter固定为console
+	TimeKey                   string            // 时间字段名称，默认ts
+	LevelKey                  string            // 级别字段名称，默认lv
+	MessageKey                string            // 消息字段名称，默认msg
+	CallerKey                 string            // 调用者字段名称，默认caller
+	TimeFormat                string            // 时间格式，可选[second|millis|nanos|rfc3339|rfc3339nano|iso8601]或Go时间layout，默认秒级时间戳
+	EnableFlatten             bool              // 是否将嵌套字段展开为以"."连接的key，logfmt总是展开，默认不展开
+	EnableColor               bool              // console编码时是否为日志级别添加颜色，默认不添加
+	Writer                    string            // 使用哪种Writer，可选[file|ali|stderr|syslog|loki]或通过RegisterWriter注册的Writer，默认file
+	AliAccessKeyID            string            // [aliWriter]阿里云sls AKID，必填
+	AliAccessKeySecret        string            // [aliWriter]阿里云sls AKSecret，必填
+	AliEndpoint               string            // [aliWriter]阿里云sls endpoint，必填
+	AliProject                string            // [aliWriter]阿里云sls Project名称，必填
+	AliLogstore               string            // [aliWriter]阿里云sls logstore名称，必填
+	AliAPIBulkSize            int               // [aliWriter]阿里云sls API单次请求发送最大日志条数，最少256条，默认256条
+	AliAPITimeout             time.Duration     // [aliWriter]阿里云sls API接口超时，默认3秒
+	AliAPIRetryCount          int               // [aliWriter]阿里云sls API接口重试次数，默认3次
+	AliAPIRetryWaitTime       time.Duration     // [aliWriter]阿里云sls API接口重试默认等待间隔，默认1秒
+	AliAPIRetryMaxWaitTime    time.Duration     // [aliWriter]阿里云sls API接口重试最大等待间隔，默认3秒
+	AliAPIMaxIdleConnsPerHost int               // [aliWriter]阿里云sls 单个Host HTTP最大空闲连接数，应当大于AliApiMaxIdleConns
+	AliAPIMaxIdleConns        int               // [aliWriter]阿里云sls HTTP最大空闲连接数
+	AliAPIIdleConnTimeout     time.Duration     // [aliWriter]阿里云sls HTTP空闲连接保活时间
+	AliSpoolDir               string            // [aliWriter]磁盘队列目录，重试后仍发送失败的日志会持久化到该目录，恢复后按顺序重放，为空不开启，默认不开启
+	AliSpoolMaxSize           int               // [aliWriter]磁盘队列最大容量，超过后写入本地ali.log，默认1024M
+	AliSpoolReplayInterval    time.Duration     // [aliWriter]磁盘队列重放间隔，默认5秒
+	SyslogNetwork             string            // [syslogWriter]网络类型，可选[udp|tcp|unix|unixgram]，默认udp
+	SyslogAddr                string            // [syslogWriter]syslog服务地址，unix类型为socket文件路径，默认127.0.0.1:514
+	SyslogFacility            string            // [syslogWriter]facility，默认local0
+	SyslogAppName             string            // [syslogWriter]RFC 5424中的APP-NAME，默认应用名
+	SyslogTimeout             time.Duration     // [syslogWriter]连接以及写入超时，默认3秒
+	LokiURL                   string            // [lokiWriter]push地址，必填，例如http://127.0.0.1:3100/loki/api/v1/push
+	LokiLabels                map[string]string // [lokiWriter]stream标签，默认app=应用名，level标签会自动加上
+	LokiTenantID              string            // [lokiWriter]多租户ID，对应X-Scope-OrgID头
+	LokiAPIBulkSize           int               // [lokiWriter]单次请求发送最大日志条数，最少256条，默认256条
+	LokiAPITimeout            time.Duration     // [lokiWriter]接口超时，默认3秒
+	LokiAPIRetryCount         int               // [lokiWriter]接口重试次数，默认3次
+	LokiAPIRetryWaitTime      time.Duration     // [lokiWriter]接口重试默认等待间隔，默认1秒
+	LokiAPIRetryMaxWaitTime   time.Duration     // [lokiWriter]接口重试最大等待间隔，默认3秒
 
 	fields        []zap.Field // 日志初始化字段
 	CallerSkip    int
 	encoderConfig *zapcore.EncoderConfig
+	rotateHook    func(filename string) // [fileWriter]日志轮转后的回调
+	core          zapcore.Core          // 自定义core，设置后不再使用Writer
 }
 
 const (
 	writerRotateFile = "file"
 	writerAliSLS     = "ali"
 	writerStderr     = "stderr"
+	writerSyslog     = "syslog"
+	writerLoki       = "loki"
 )
 
 // filename ...
@@ -55,6 +86,11 @@ func (config *Config) filename() string {
 	return fmt.Sprintf("%s/%s", config.Dir, config.Name)
 }
 
+// EncoderConfig 返回日志编码配置，供自定义Writer构造编码器使用
+func (config *Config) EncoderConfig() *zapcore.EncoderConfig {
+	return config.encoderConfig
+}
+
 // DefaultConfig ...
 func DefaultConfig() *Config {
 	dir := "./logs"
@@ -74,7 +110,7 @@ func DefaultConfig() *Config {
 		CallerSkip:                1,
 		EnableAddCaller:           false,
 		EnableAsync:               true,
-		encoderConfig:             defaultZapConfig(),
+		Encoder:                   encoderJSON,
 		Writer:                    writerRotateFile,
 		AliAPIBulkSize:            256,
 		AliAPITimeout:             3 * time.Second,
@@ -84,5 +120,17 @@ func DefaultConfig() *Config {
 		AliAPIMaxIdleConnsPerHost: 20,
 		AliAPIMaxIdleConns:        25,
 		AliAPIIdleConnTimeout:     30 * time.Second,
+		AliSpoolMaxSize:           1024, // 1024M
+		AliSpoolReplayInterval:    5 * time.Second,
+		SyslogNetwork:             "udp",
+		SyslogAddr:                "127.0.0.1:514",
+		SyslogFacility:            "local0",
+		SyslogAppName:             eapp.Name(),
+		SyslogTimeout:             3 * time.Second,
+		LokiAPIBulkSize:           256,
+		LokiAPITimeout:            3 * time.Second,
+		LokiAPIRetryCount:         3,
+		LokiAPIRetryWaitTime:      1 * time.Second,
+		LokiAPIRetryMaxWaitTime:   3 * time.Second,
 	}
 }
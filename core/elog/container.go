@@ -34,19 +34,14 @@ func (c *Container) Build(options ...Option) *Component {
 		option(c)
 	}
 
-	if eapp.IsDevelopmentMode() {
+	// 自定义core时日志不经过Writer，不需要调试模式的终端输出
+	if eapp.IsDevelopmentMode() && c.config.core == nil {
 		c.config.Debug = true           // 调试模式，终端输出
 		c.config.EnableAsync = false    // 调试模式，同步输出
 		c.config.EnableAddCaller = true // 调试模式，增加行号输出
 	}
 
-	if c.config.encoderConfig == nil {
-		c.config.encoderConfig = defaultZapConfig()
-	}
-
-	if c.config.Debug {
-		c.config.encoderConfig = defaultDebugConfig()
-	}
+	c.config.applyEncoderConfig()
 
 	if eapp.EnableLoggerAddApp() {
 		c.config.fields = append(c.config.fields, FieldApp(eapp.Name()))
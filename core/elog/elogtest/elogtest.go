@@ -0,0 +1,132 @@
+// Package elogtest 提供测试使用的内存日志，可以直接断言记录下来的日志，而不用读取./logs下的文件
+package elogtest
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// LoggedEntry 记录下来的一条日志
+type LoggedEntry = observer.LoggedEntry
+
+// ObservedLogs 记录在内存中的日志，所有Filter方法返回新的ObservedLogs，不影响原有日志
+type ObservedLogs struct {
+	*observer.ObservedLogs
+}
+
+// New 构造一个将日志记录在内存中的组件，默认记录debug及以上级别，可以通过elog.WithLevel调整
+func New(options ...elog.Option) (*elog.Component, *ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	opts := []elog.Option{
+		elog.WithLevel("debug"),
+		elog.WithDebug(false),
+		elog.WithEnableAsync(false),
+	}
+	opts = append(opts, options...)
+	opts = append(opts, elog.WithZapCore(core))
+	return elog.DefaultContainer().Build(opts...), &ObservedLogs{ObservedLogs: logs}
+}
+
+// Swap 在测试期间将elog.DefaultLogger、elog.EgoLogger替换为内存日志，测试结束后自动还原
+func Swap(t testing.TB, options ...elog.Option) *ObservedLogs {
+	t.Helper()
+	logger, logs := New(options...)
+	defaultLogger, egoLogger := elog.DefaultLogger, elog.EgoLogger
+	elog.DefaultLogger, elog.EgoLogger = logger, logger
+	t.Cleanup(func() {
+		elog.DefaultLogger, elog.EgoLogger = defaultLogger, egoLogger
+	})
+	return logs
+}
+
+func (o *ObservedLogs) filter(match func(LoggedEntry) bool) *ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	for _, e := range o.All() {
+		if match(e) {
+			_ = core.Write(e.Entry, e.Context)
+		}
+	}
+	return &ObservedLogs{ObservedLogs: logs}
+}
+
+// FilterLevel 过滤出指定级别的日志
+func (o *ObservedLogs) FilterLevel(lv elog.Level) *ObservedLogs {
+	return o.filter(func(e LoggedEntry) bool {
+		return e.Level == lv
+	})
+}
+
+// FilterMessage 过滤出消息完全相同的日志
+func (o *ObservedLogs) FilterMessage(msg string) *ObservedLogs {
+	return o.filter(func(e LoggedEntry) bool {
+		return e.Message == msg
+	})
+}
+
+// FilterMessageSnippet 过滤出消息包含snippet的日志
+func (o *ObservedLogs) FilterMessageSnippet(snippet string) *ObservedLogs {
+	return o.filter(func(e LoggedEntry) bool {
+		return strings.Contains(e.Message, snippet)
+	})
+}
+
+// FilterField 过滤出包含该字段，并且字段值相同的日志
+func (o *ObservedLogs) FilterField(field elog.Field) *ObservedLogs {
+	return o.filter(func(e LoggedEntry) bool {
+		for _, f := range e.Context {
+			if f.Equals(field) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FilterFieldKey 过滤出包含该字段名的日志
+func (o *ObservedLogs) FilterFieldKey(key string) *ObservedLogs {
+	return o.filter(func(e LoggedEntry) bool {
+		for _, f := range e.Context {
+			if f.Key == key {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// AssertLen 断言日志条数
+func (o *ObservedLogs) AssertLen(t testing.TB, n int) {
+	t.Helper()
+	if o.Len() != n {
+		t.Errorf("expected %d log entries, got %d: %s", n, o.Len(), o.messages())
+	}
+}
+
+// AssertLogged 断言至少记录了一条日志
+func (o *ObservedLogs) AssertLogged(t testing.TB) {
+	t.Helper()
+	if o.Len() == 0 {
+		t.Errorf("expected log entries, got none")
+	}
+}
+
+// AssertNotLogged 断言没有记录日志
+func (o *ObservedLogs) AssertNotLogged(t testing.TB) {
+	t.Helper()
+	if o.Len() != 0 {
+		t.Errorf("expected no log entries, got %d: %s", o.Len(), o.messages())
+	}
+}
+
+func (o *ObservedLogs) messages() string {
+	msgs := make([]string, 0, o.Len())
+	for _, e := range o.All() {
+		msgs = append(msgs, e.Level.String()+" "+e.Message)
+	}
+	return "[" + strings.Join(msgs, ", ") + "]"
+}
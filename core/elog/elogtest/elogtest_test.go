@@ -0,0 +1,39 @@
+package elogtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func TestNew(t *testing.T) {
+	logger, logs := New(elog.WithLevel("info"))
+	logger.Debug("ignored")
+	logger.Info("hello", elog.String("name", "ego"))
+	logger.With(elog.FieldComponent("test")).Error("boom", elog.Int("code", 500))
+
+	logs.AssertLen(t, 2)
+	logs.FilterLevel(elog.ErrorLevel).AssertLen(t, 1)
+	logs.FilterMessage("hello").FilterField(elog.String("name", "ego")).AssertLogged(t)
+	logs.FilterMessageSnippet("bo").FilterFieldKey("code").AssertLen(t, 1)
+	logs.FilterMessage("ignored").AssertNotLogged(t)
+
+	logger.SetLevel(elog.DebugLevel)
+	logger.Debug("debug")
+	logs.FilterLevel(elog.DebugLevel).AssertLen(t, 1)
+}
+
+func TestSwap(t *testing.T) {
+	defaultLogger, egoLogger := elog.DefaultLogger, elog.EgoLogger
+	t.Run("swap", func(t *testing.T) {
+		logs := Swap(t)
+		elog.Info("business")
+		elog.EgoLogger.Warn("framework")
+		logs.AssertLen(t, 2)
+		assert.Equal(t, "business", logs.All()[0].Message)
+	})
+	assert.Equal(t, defaultLogger, elog.DefaultLogger)
+	assert.Equal(t, egoLogger, elog.EgoLogger)
+}
@@ -0,0 +1,87 @@
+package elog
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	encoderJSON    = "json"
+	encoderConsole = "console"
+	encoderLogfmt  = "logfmt"
+)
+
+// newEncoder 根据配置构造编码器
+func (config *Config) newEncoder() zapcore.Encoder {
+	var enc zapcore.Encoder
+	switch config.Encoder {
+	case "", encoderJSON:
+		enc = zapcore.NewJSONEncoder(*config.encoderConfig)
+	case encoderConsole:
+		enc = zapcore.NewConsoleEncoder(*config.encoderConfig)
+	case encoderLogfmt:
+		// logfmt没有嵌套结构，总是展开
+		return newLogfmtEncoder(*config.encoderConfig)
+	default:
+		panic("unsupported encoder: " + config.Encoder)
+	}
+	if config.EnableFlatten {
+		enc = newFlattenEncoder(enc)
+	}
+	return enc
+}
+
+// applyEncoderConfig 将配置里的key名称、时间格式覆盖到编码配置上
+// 未通过WithEncoderConfig指定编码配置时，根据是否调试模式选择默认编码配置
+func (config *Config) applyEncoderConfig() {
+	if config.encoderConfig == nil {
+		config.encoderConfig = defaultZapConfig()
+		if config.Debug {
+			config.encoderConfig = defaultDebugConfig()
+		}
+	}
+	ec := *config.encoderConfig
+	if config.TimeKey != "" {
+		ec.TimeKey = config.TimeKey
+	}
+	if config.LevelKey != "" {
+		ec.LevelKey = config.LevelKey
+	}
+	if config.MessageKey != "" {
+		ec.MessageKey = config.MessageKey
+	}
+	if config.CallerKey != "" {
+		ec.CallerKey = config.CallerKey
+	}
+	if config.TimeFormat != "" {
+		ec.EncodeTime = newTimeEncoder(config.TimeFormat)
+	}
+	if config.EnableColor && config.Encoder == encoderConsole {
+		ec.EncodeLevel = DebugEncodeLevel
+	}
+	config.encoderConfig = &ec
+}
+
+// newTimeEncoder 根据时间格式构造时间编码器
+// 可选[second|millis|nanos|rfc3339|rfc3339nano|iso8601]，其他值作为Go时间layout
+func newTimeEncoder(format string) zapcore.TimeEncoder {
+	switch format {
+	case "second":
+		return timeEncoder
+	case "millis":
+		return zapcore.EpochMillisTimeEncoder
+	case "nanos":
+		return zapcore.EpochNanosTimeEncoder
+	case "rfc3339", "RFC3339":
+		return zapcore.RFC3339TimeEncoder
+	case "rfc3339nano", "RFC3339Nano":
+		return zapcore.RFC3339NanoTimeEncoder
+	case "iso8601", "ISO8601":
+		return zapcore.ISO8601TimeEncoder
+	default:
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(t.Format(format))
+		}
+	}
+}
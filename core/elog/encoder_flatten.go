@@ -0,0 +1,183 @@
+package elog
+
+import (
+	"sort"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// flattenEncoder 将嵌套字段展开为以"."连接的key，例如 zap.Namespace("req") 之后的 zap.String("method", "GET") 输出为 req.method=GET
+type flattenEncoder struct {
+	zapcore.Encoder
+	prefix string
+}
+
+func newFlattenEncoder(enc zapcore.Encoder) zapcore.Encoder {
+	return &flattenEncoder{Encoder: enc}
+}
+
+func (f *flattenEncoder) key(key string) string {
+	if f.prefix == "" {
+		return key
+	}
+	return f.prefix + "." + key
+}
+
+// Clone ...
+func (f *flattenEncoder) Clone() zapcore.Encoder {
+	return &flattenEncoder{Encoder: f.Encoder.Clone(), prefix: f.prefix}
+}
+
+// EncodeEntry ...
+func (f *flattenEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	if len(fields) == 0 {
+		return f.Encoder.EncodeEntry(ent, nil)
+	}
+	final := &flattenEncoder{Encoder: f.Encoder.Clone(), prefix: f.prefix}
+	for _, field := range fields {
+		field.AddTo(final)
+	}
+	return final.Encoder.EncodeEntry(ent, nil)
+}
+
+// AddObject 展开对象
+func (f *flattenEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return obj.MarshalLogObject(&flattenEncoder{Encoder: f.Encoder, prefix: f.key(key)})
+}
+
+// AddReflected 展开map[string]interface{}，其他类型保持原样
+func (f *flattenEncoder) AddReflected(key string, obj interface{}) error {
+	if m, ok := obj.(map[string]interface{}); ok {
+		nested := &flattenEncoder{Encoder: f.Encoder, prefix: f.key(key)}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		// map无序，按key排序保证输出稳定
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := nested.AddReflected(k, m[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return f.Encoder.AddReflected(f.key(key), obj)
+}
+
+// OpenNamespace 之后的字段都加上该前缀
+func (f *flattenEncoder) OpenNamespace(key string) {
+	f.prefix = f.key(key)
+}
+
+// AddArray ...
+func (f *flattenEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return f.Encoder.AddArray(f.key(key), arr)
+}
+
+// AddBinary ...
+func (f *flattenEncoder) AddBinary(key string, value []byte) {
+	f.Encoder.AddBinary(f.key(key), value)
+}
+
+// AddByteString ...
+func (f *flattenEncoder) AddByteString(key string, value []byte) {
+	f.Encoder.AddByteString(f.key(key), value)
+}
+
+// AddBool ...
+func (f *flattenEncoder) AddBool(key string, value bool) {
+	f.Encoder.AddBool(f.key(key), value)
+}
+
+// AddComplex128 ...
+func (f *flattenEncoder) AddComplex128(key string, value complex128) {
+	f.Encoder.AddComplex128(f.key(key), value)
+}
+
+// AddComplex64 ...
+func (f *flattenEncoder) AddComplex64(key string, value complex64) {
+	f.Encoder.AddComplex64(f.key(key), value)
+}
+
+// AddDuration ...
+func (f *flattenEncoder) AddDuration(key string, value time.Duration) {
+	f.Encoder.AddDuration(f.key(key), value)
+}
+
+// AddFloat64 ...
+func (f *flattenEncoder) AddFloat64(key string, value float64) {
+	f.Encoder.AddFloat64(f.key(key), value)
+}
+
+// AddFloat32 ...
+func (f *flattenEncoder) AddFloat32(key string, value float32) {
+	f.Encoder.AddFloat32(f.key(key), value)
+}
+
+// AddInt ...
+func (f *flattenEncoder) AddInt(key string, value int) {
+	f.Encoder.AddInt(f.key(key), value)
+}
+
+// AddInt64 ...
+func (f *flattenEncoder) AddInt64(key string, value int64) {
+	f.Encoder.AddInt64(f.key(key), value)
+}
+
+// AddInt32 ...
+func (f *flattenEncoder) AddInt32(key string, value int32) {
+	f.Encoder.AddInt32(f.key(key), value)
+}
+
+// AddInt16 ...
+func (f *flattenEncoder) AddInt16(key string, value int16) {
+	f.Encoder.AddInt16(f.key(key), value)
+}
+
+// AddInt8 ...
+func (f *flattenEncoder) AddInt8(key string, value int8) {
+	f.Encoder.AddInt8(f.key(key), value)
+}
+
+// AddString ...
+func (f *flattenEncoder) AddString(key, value string) {
+	f.Encoder.AddString(f.key(key), value)
+}
+
+// AddTime ...
+func (f *flattenEncoder) AddTime(key string, value time.Time) {
+	f.Encoder.AddTime(f.key(key), value)
+}
+
+// AddUint ...
+func (f *flattenEncoder) AddUint(key string, value uint) {
+	f.Encoder.AddUint(f.key(key), value)
+}
+
+// AddUint64 ...
+func (f *flattenEncoder) AddUint64(key string, value uint64) {
+	f.Encoder.AddUint64(f.key(key), value)
+}
+
+// AddUint32 ...
+func (f *flattenEncoder) AddUint32(key string, value uint32) {
+	f.Encoder.AddUint32(f.key(key), value)
+}
+
+// AddUint16 ...
+func (f *flattenEncoder) AddUint16(key string, value uint16) {
+	f.Encoder.AddUint16(f.key(key), value)
+}
+
+// AddUint8 ...
+func (f *flattenEncoder) AddUint8(key string, value uint8) {
+	f.Encoder.AddUint8(f.key(key), value)
+}
+
+// AddUintptr ...
+func (f *flattenEncoder) AddUintptr(key string, value uintptr) {
+	f.Encoder.AddUintptr(f.key(key), value)
+}
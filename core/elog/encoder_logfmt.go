@@ -0,0 +1,356 @@
+package elog
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder 以 key=value 的logfmt格式输出日志，嵌套字段由外层的flattenEncoder展开
+type logfmtEncoder struct {
+	*zapcore.EncoderConfig
+	buf *buffer.Buffer
+}
+
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return newFlattenEncoder(&logfmtEncoder{
+		EncoderConfig: &cfg,
+		buf:           logfmtPool.Get(),
+	})
+}
+
+// Clone ...
+func (enc *logfmtEncoder) Clone() zapcore.Encoder {
+	return enc.clone()
+}
+
+func (enc *logfmtEncoder) clone() *logfmtEncoder {
+	clone := &logfmtEncoder{
+		EncoderConfig: enc.EncoderConfig,
+		buf:           logfmtPool.Get(),
+	}
+	_, _ = clone.buf.Write(enc.buf.Bytes())
+	return clone
+}
+
+// EncodeEntry ...
+func (enc *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := &logfmtEncoder{
+		EncoderConfig: enc.EncoderConfig,
+		buf:           logfmtPool.Get(),
+	}
+	if final.TimeKey != "" {
+		final.addKey(final.TimeKey)
+		if final.EncodeTime != nil {
+			final.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { final.EncodeTime(ent.Time, arr) })
+		} else {
+			final.buf.AppendInt(ent.Time.Unix())
+		}
+	}
+	if final.LevelKey != "" {
+		final.addKey(final.LevelKey)
+		if final.EncodeLevel != nil {
+			final.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { final.EncodeLevel(ent.Level, arr) })
+		} else {
+			final.appendString(ent.Level.String())
+		}
+	}
+	if ent.LoggerName != "" && final.NameKey != "" {
+		final.AddString(final.NameKey, ent.LoggerName)
+	}
+	if ent.Caller.Defined && final.CallerKey != "" {
+		final.addKey(final.CallerKey)
+		if final.EncodeCaller != nil {
+			final.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { final.EncodeCaller(ent.Caller, arr) })
+		} else {
+			final.appendString(ent.Caller.String())
+		}
+	}
+	if final.MessageKey != "" {
+		final.AddString(final.MessageKey, ent.Message)
+	}
+	if enc.buf.Len() > 0 {
+		final.addSeparator()
+		_, _ = final.buf.Write(enc.buf.Bytes())
+	}
+	for _, field := range fields {
+		field.AddTo(final)
+	}
+	if ent.Stack != "" && final.StacktraceKey != "" {
+		final.AddString(final.StacktraceKey, ent.Stack)
+	}
+	if final.LineEnding != "" {
+		final.buf.AppendString(final.LineEnding)
+	} else {
+		final.buf.AppendString(zapcore.DefaultLineEnding)
+	}
+	return final.buf, nil
+}
+
+func (enc *logfmtEncoder) addSeparator() {
+	if enc.buf.Len() > 0 {
+		enc.buf.AppendByte(' ')
+	}
+}
+
+func (enc *logfmtEncoder) addKey(key string) {
+	enc.addSeparator()
+	enc.buf.AppendString(key)
+	enc.buf.AppendByte('=')
+}
+
+// appendEncoded 使用EncoderConfig中的各种Encode函数编码，编码出多个值时输出为数组
+func (enc *logfmtEncoder) appendEncoded(encode func(arr zapcore.PrimitiveArrayEncoder)) {
+	arr := &sliceArrayEncoder{}
+	encode(arr)
+	if len(arr.elems) == 1 {
+		enc.appendValue(arr.elems[0])
+		return
+	}
+	enc.appendValue(arr.elems)
+}
+
+func (enc *logfmtEncoder) appendValue(value interface{}) {
+	switch v := value.(type) {
+	case string:
+		enc.appendString(v)
+	case bool:
+		enc.buf.AppendBool(v)
+	case int64:
+		enc.buf.AppendInt(v)
+	case uint64:
+		enc.buf.AppendUint(v)
+	case float64:
+		enc.buf.AppendFloat(v, 64)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			enc.appendString(fmt.Sprint(v))
+			return
+		}
+		enc.appendString(string(b))
+	}
+}
+
+// appendString 包含空格、引号、等号或控制字符的值需要加引号
+func (enc *logfmtEncoder) appendString(s string) {
+	if needsQuote(s) {
+		enc.buf.AppendString(strconv.Quote(s))
+		return
+	}
+	enc.buf.AppendString(s)
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// AddArray ...
+func (enc *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	elems := &sliceArrayEncoder{}
+	if err := arr.MarshalLogArray(elems); err != nil {
+		return err
+	}
+	enc.addKey(key)
+	enc.appendValue(elems.elems)
+	return nil
+}
+
+// AddObject ...
+func (enc *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := obj.MarshalLogObject(m); err != nil {
+		return err
+	}
+	enc.addKey(key)
+	enc.appendValue(m.Fields)
+	return nil
+}
+
+// AddReflected ...
+func (enc *logfmtEncoder) AddReflected(key string, value interface{}) error {
+	enc.addKey(key)
+	switch v := value.(type) {
+	case string:
+		enc.appendString(v)
+		return nil
+	case fmt.Stringer:
+		enc.appendString(v.String())
+		return nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	enc.appendString(string(b))
+	return nil
+}
+
+// OpenNamespace 由外层的flattenEncoder处理
+func (enc *logfmtEncoder) OpenNamespace(key string) {}
+
+// AddBinary ...
+func (enc *logfmtEncoder) AddBinary(key string, value []byte) {
+	enc.AddString(key, base64.StdEncoding.EncodeToString(value))
+}
+
+// AddByteString ...
+func (enc *logfmtEncoder) AddByteString(key string, value []byte) {
+	enc.AddString(key, string(value))
+}
+
+// AddBool ...
+func (enc *logfmtEncoder) AddBool(key string, value bool) {
+	enc.addKey(key)
+	enc.buf.AppendBool(value)
+}
+
+// AddComplex128 ...
+func (enc *logfmtEncoder) AddComplex128(key string, value complex128) {
+	enc.AddString(key, strconv.FormatComplex(value, 'g', -1, 128))
+}
+
+// AddComplex64 ...
+func (enc *logfmtEncoder) AddComplex64(key string, value complex64) {
+	enc.AddString(key, strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+
+// AddDuration ...
+func (enc *logfmtEncoder) AddDuration(key string, value time.Duration) {
+	enc.addKey(key)
+	if enc.EncodeDuration != nil {
+		enc.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeDuration(value, arr) })
+		return
+	}
+	enc.buf.AppendInt(int64(value))
+}
+
+// AddFloat64 ...
+func (enc *logfmtEncoder) AddFloat64(key string, value float64) {
+	enc.addKey(key)
+	enc.buf.AppendFloat(value, 64)
+}
+
+// AddFloat32 ...
+func (enc *logfmtEncoder) AddFloat32(key string, value float32) {
+	enc.addKey(key)
+	enc.buf.AppendFloat(float64(value), 32)
+}
+
+// AddInt ...
+func (enc *logfmtEncoder) AddInt(key string, value int) { enc.AddInt64(key, int64(value)) }
+
+// AddInt64 ...
+func (enc *logfmtEncoder) AddInt64(key string, value int64) {
+	enc.addKey(key)
+	enc.buf.AppendInt(value)
+}
+
+// AddInt32 ...
+func (enc *logfmtEncoder) AddInt32(key string, value int32) { enc.AddInt64(key, int64(value)) }
+
+// AddInt16 ...
+func (enc *logfmtEncoder) AddInt16(key string, value int16) { enc.AddInt64(key, int64(value)) }
+
+// AddInt8 ...
+func (enc *logfmtEncoder) AddInt8(key string, value int8) { enc.AddInt64(key, int64(value)) }
+
+// AddString ...
+func (enc *logfmtEncoder) AddString(key, value string) {
+	enc.addKey(key)
+	enc.appendString(value)
+}
+
+// AddTime ...
+func (enc *logfmtEncoder) AddTime(key string, value time.Time) {
+	enc.addKey(key)
+	if enc.EncodeTime != nil {
+		enc.appendEncoded(func(arr zapcore.PrimitiveArrayEncoder) { enc.EncodeTime(value, arr) })
+		return
+	}
+	enc.buf.AppendInt(value.UnixNano())
+}
+
+// AddUint ...
+func (enc *logfmtEncoder) AddUint(key string, value uint) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint64 ...
+func (enc *logfmtEncoder) AddUint64(key string, value uint64) {
+	enc.addKey(key)
+	enc.buf.AppendUint(value)
+}
+
+// AddUint32 ...
+func (enc *logfmtEncoder) AddUint32(key string, value uint32) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint16 ...
+func (enc *logfmtEncoder) AddUint16(key string, value uint16) { enc.AddUint64(key, uint64(value)) }
+
+// AddUint8 ...
+func (enc *logfmtEncoder) AddUint8(key string, value uint8) { enc.AddUint64(key, uint64(value)) }
+
+// AddUintptr ...
+func (enc *logfmtEncoder) AddUintptr(key string, value uintptr) { enc.AddUint64(key, uint64(value)) }
+
+// sliceArrayEncoder 收集数组元素
+type sliceArrayEncoder struct {
+	elems []interface{}
+}
+
+func (s *sliceArrayEncoder) AppendArray(v zapcore.ArrayMarshaler) error {
+	enc := &sliceArrayEncoder{}
+	err := v.MarshalLogArray(enc)
+	s.elems = append(s.elems, enc.elems)
+	return err
+}
+
+func (s *sliceArrayEncoder) AppendObject(v zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	err := v.MarshalLogObject(m)
+	s.elems = append(s.elems, m.Fields)
+	return err
+}
+
+func (s *sliceArrayEncoder) AppendReflected(v interface{}) error {
+	s.elems = append(s.elems, v)
+	return nil
+}
+
+func (s *sliceArrayEncoder) AppendBool(v bool)              { s.elems = append(s.elems, v) }
+func (s *sliceArrayEncoder) AppendByteString(v []byte)      { s.elems = append(s.elems, string(v)) }
+func (s *sliceArrayEncoder) AppendComplex128(v complex128)  { s.elems = append(s.elems, fmt.Sprint(v)) }
+func (s *sliceArrayEncoder) AppendComplex64(v complex64)    { s.elems = append(s.elems, fmt.Sprint(v)) }
+func (s *sliceArrayEncoder) AppendDuration(v time.Duration) { s.elems = append(s.elems, v.String()) }
+func (s *sliceArrayEncoder) AppendFloat64(v float64)        { s.elems = append(s.elems, v) }
+func (s *sliceArrayEncoder) AppendFloat32(v float32)        { s.elems = append(s.elems, float64(v)) }
+func (s *sliceArrayEncoder) AppendInt(v int)                { s.elems = append(s.elems, int64(v)) }
+func (s *sliceArrayEncoder) AppendInt64(v int64)            { s.elems = append(s.elems, v) }
+func (s *sliceArrayEncoder) AppendInt32(v int32)            { s.elems = append(s.elems, int64(v)) }
+func (s *sliceArrayEncoder) AppendInt16(v int16)            { s.elems = append(s.elems, int64(v)) }
+func (s *sliceArrayEncoder) AppendInt8(v int8)              { s.elems = append(s.elems, int64(v)) }
+func (s *sliceArrayEncoder) AppendString(v string)          { s.elems = append(s.elems, v) }
+func (s *sliceArrayEncoder) AppendTime(v time.Time) {
+	s.elems = append(s.elems, v.Format(time.RFC3339Nano))
+}
+func (s *sliceArrayEncoder) AppendUint(v uint)       { s.elems = append(s.elems, uint64(v)) }
+func (s *sliceArrayEncoder) AppendUint64(v uint64)   { s.elems = append(s.elems, v) }
+func (s *sliceArrayEncoder) AppendUint32(v uint32)   { s.elems = append(s.elems, uint64(v)) }
+func (s *sliceArrayEncoder) AppendUint16(v uint16)   { s.elems = append(s.elems, uint64(v)) }
+func (s *sliceArrayEncoder) AppendUint8(v uint8)     { s.elems = append(s.elems, uint64(v)) }
+func (s *sliceArrayEncoder) AppendUintptr(v uintptr) { s.elems = append(s.elems, uint64(v)) }
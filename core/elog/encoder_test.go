@@ -0,0 +1,90 @@
+package elog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestEncoderLogger(config *Config) (*zap.Logger, *bytes.Buffer) {
+	config.applyEncoderConfig()
+	buf := &bytes.Buffer{}
+	core := zapcore.NewCore(config.newEncoder(), zapcore.AddSync(buf), zapcore.DebugLevel)
+	return zap.New(core), buf
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	config := DefaultConfig()
+	config.Encoder = encoderLogfmt
+	logger, buf := newTestEncoderLogger(config)
+	logger.With(String("prefix", "PREFIX")).Info("hello world",
+		Int("count", 1),
+		String("empty", ""),
+		Namespace("req"),
+		String("method", "GET"),
+		Any("headers", map[string]interface{}{"host": "a.com"}),
+	)
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	parts := strings.SplitN(line, " ", 2)
+	assert.True(t, strings.HasPrefix(parts[0], "ts="))
+	assert.Equal(t, `lv=info msg="hello world" prefix=PREFIX count=1 empty="" req.method=GET req.headers.host=a.com`, parts[1])
+}
+
+func TestFlattenJSONEncoder(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableFlatten = true
+	logger, buf := newTestEncoderLogger(config)
+	logger.Info("hello", Namespace("req"), String("method", "GET"))
+	assert.Contains(t, buf.String(), `"req.method":"GET"`)
+}
+
+func TestEncoderKeysAndTimeFormat(t *testing.T) {
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Date(2021, 8, 1, 12, 30, 0, 123456789, time.UTC), Message: "hello"}
+	config := DefaultConfig()
+	config.TimeKey = "@timestamp"
+	config.LevelKey = "level"
+	config.MessageKey = "message"
+	config.TimeFormat = "millis"
+	config.applyEncoderConfig()
+	buf, err := config.newEncoder().EncodeEntry(ent, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"level":"info"`)
+	assert.Contains(t, buf.String(), `"message":"hello"`)
+	assert.Contains(t, buf.String(), `"@timestamp":1627821000123`)
+
+	config = DefaultConfig()
+	config.TimeFormat = "rfc3339"
+	config.applyEncoderConfig()
+	buf, err = config.newEncoder().EncodeEntry(ent, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"ts":"2021-08-01T12:30:00Z"`)
+}
+
+func TestDebugRespectsEncoderConfig(t *testing.T) {
+	encoderConfig := defaultZapConfig()
+	encoderConfig.MessageKey = "custom_msg"
+	logger := DefaultContainer().Build(WithDebug(true), WithEncoderConfig(encoderConfig))
+	assert.Equal(t, "custom_msg", logger.config.EncoderConfig().MessageKey)
+
+	// 未指定编码配置时使用调试模式的彩色输出
+	logger = DefaultContainer().Build(WithDebug(true))
+	buf, err := zapcore.NewConsoleEncoder(*logger.config.EncoderConfig()).EncodeEntry(zapcore.Entry{Level: zapcore.InfoLevel}, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "\x1b[")
+}
+
+func TestConsoleEncoderColor(t *testing.T) {
+	config := DefaultConfig()
+	config.Encoder = encoderConsole
+	config.EnableColor = true
+	logger, buf := newTestEncoderLogger(config)
+	logger.Warn("hello")
+	assert.Contains(t, buf.String(), "\x1b[")
+	assert.Contains(t, buf.String(), "WARN")
+}
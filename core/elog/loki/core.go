@@ -0,0 +1,91 @@
+package loki
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// NewCore creates a Core that pushes logs to a Loki compatible HTTP endpoint.
+func NewCore(ops ...Option) (zapcore.Core, func() error) {
+	var c config
+	for _, o := range ops {
+		o(&c)
+	}
+	w, err := newWriter(c)
+	if err != nil {
+		panic(fmt.Errorf("NewCore fail, %w", err))
+	}
+
+	core := &ioCore{
+		LevelEnabler: c.levelEnabler,
+		enc:          c.encoder,
+		writer:       w,
+	}
+	closeFunc := func() error {
+		w.cancel()
+		return w.flush()
+	}
+	return core, closeFunc
+}
+
+type ioCore struct {
+	zapcore.LevelEnabler
+	enc    zapcore.Encoder
+	fields []zapcore.Field
+	writer *writer
+}
+
+func (c *ioCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := c.clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	clone.fields = append(clone.fields, fields...)
+	return clone
+}
+
+func (c *ioCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *ioCore) Write(ent zapcore.Entry, fields []zapcore.Field) (err error) {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	e := &entry{
+		ent:    ent,
+		fields: make([]zapcore.Field, 0, len(c.fields)+len(fields)),
+		line:   string(buf.Bytes()),
+	}
+	buf.Free()
+	e.fields = append(e.fields, c.fields...)
+	e.fields = append(e.fields, fields...)
+
+	if err = c.writer.write(e); err != nil {
+		return err
+	}
+	if ent.Level > zapcore.ErrorLevel {
+		err = c.Sync()
+	}
+	return
+}
+
+func (c *ioCore) Sync() error {
+	return c.writer.flush()
+}
+
+func (c *ioCore) clone() *ioCore {
+	fields := make([]zapcore.Field, 0, len(c.fields))
+	fields = append(fields, c.fields...)
+	return &ioCore{
+		LevelEnabler: c.LevelEnabler,
+		enc:          c.enc.Clone(),
+		fields:       fields,
+		writer:       c.writer,
+	}
+}
@@ -0,0 +1,107 @@
+package loki
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		LevelKey:    "lv",
+		MessageKey:  "msg",
+		EncodeLevel: zapcore.LowercaseLevelEncoder,
+		LineEnding:  zapcore.DefaultLineEnding,
+	})
+}
+
+func TestPush(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []pushRequest
+		tenants  []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req pushRequest
+		assert.NoError(t, json.Unmarshal(body, &req))
+		mu.Lock()
+		requests = append(requests, req)
+		tenants = append(tenants, r.Header.Get(tenantIDHeader))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	core, closeFunc := NewCore(
+		WithEncoder(newTestEncoder()),
+		WithURL(ts.URL+"/loki/api/v1/push"),
+		WithLabels(map[string]string{"app": "test"}),
+		WithTenantID("tenant-1"),
+		WithLevelEnabler(zapcore.InfoLevel),
+		WithFlushBufferSize(1024*1024),
+		WithFlushBufferInterval(time.Hour),
+	)
+	logger := zap.New(core)
+	logger.Info("a")
+	logger.Warn("b")
+	logger.Info("c")
+	logger.Debug("ignored")
+	assert.NoError(t, closeFunc())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "tenant-1", tenants[0])
+	streams := requests[0].Streams
+	assert.Len(t, streams, 2)
+	assert.Equal(t, map[string]string{"app": "test", "level": "info"}, streams[0].Stream)
+	assert.Len(t, streams[0].Values, 2)
+	assert.Contains(t, streams[0].Values[0][1], `"msg":"a"`)
+	assert.Contains(t, streams[0].Values[1][1], `"msg":"c"`)
+	assert.Equal(t, map[string]string{"app": "test", "level": "warn"}, streams[1].Stream)
+	assert.Contains(t, streams[1].Values[0][1], `"msg":"b"`)
+}
+
+type recordCore struct {
+	zapcore.LevelEnabler
+	entries []zapcore.Entry
+}
+
+func (r *recordCore) With([]zapcore.Field) zapcore.Core { return r }
+func (r *recordCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, r)
+}
+func (r *recordCore) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	r.entries = append(r.entries, ent)
+	return nil
+}
+func (r *recordCore) Sync() error { return nil }
+
+func TestPushFailFallback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	fallback := &recordCore{LevelEnabler: zapcore.DebugLevel}
+	core, closeFunc := NewCore(
+		WithEncoder(newTestEncoder()),
+		WithURL(ts.URL),
+		WithLevelEnabler(zapcore.InfoLevel),
+		WithFlushBufferInterval(time.Hour),
+		WithFallbackCore(fallback),
+	)
+	zap.New(core).Info("lost")
+	assert.NoError(t, closeFunc())
+	assert.Len(t, fallback.entries, 1)
+	assert.Equal(t, "lost", fallback.entries[0].Message)
+}
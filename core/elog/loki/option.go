@@ -0,0 +1,101 @@
+package loki
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Option 可选项
+type Option func(c *config)
+
+// WithEncoder ...
+func WithEncoder(enc zapcore.Encoder) Option {
+	return func(c *config) {
+		c.encoder = enc
+	}
+}
+
+// WithURL 设置push地址，例如http://127.0.0.1:3100/loki/api/v1/push
+func WithURL(url string) Option {
+	return func(c *config) {
+		c.url = url
+	}
+}
+
+// WithLabels 设置stream的静态标签，level标签会自动加上
+func WithLabels(labels map[string]string) Option {
+	return func(c *config) {
+		c.labels = labels
+	}
+}
+
+// WithTenantID 设置多租户ID，对应X-Scope-OrgID头
+func WithTenantID(tenantID string) Option {
+	return func(c *config) {
+		c.tenantID = tenantID
+	}
+}
+
+// WithLevelEnabler ...
+func WithLevelEnabler(lv zapcore.LevelEnabler) Option {
+	return func(c *config) {
+		c.levelEnabler = lv
+	}
+}
+
+// WithFlushBufferSize ...
+func WithFlushBufferSize(flushBufferSize int) Option {
+	return func(c *config) {
+		c.flushBufferSize = int32(flushBufferSize)
+	}
+}
+
+// WithFlushBufferInterval ...
+func WithFlushBufferInterval(flushBufferInterval time.Duration) Option {
+	return func(c *config) {
+		c.flushBufferInterval = flushBufferInterval
+	}
+}
+
+// WithAPIBulkSize ...
+func WithAPIBulkSize(apiBulkSize int) Option {
+	return func(c *config) {
+		c.apiBulkSize = apiBulkSize
+	}
+}
+
+// WithAPITimeout ...
+func WithAPITimeout(apiTimeout time.Duration) Option {
+	return func(c *config) {
+		c.apiTimeout = apiTimeout
+	}
+}
+
+// WithAPIRetryCount ...
+func WithAPIRetryCount(apiRetryCount int) Option {
+	return func(c *config) {
+		c.apiRetryCount = apiRetryCount
+	}
+}
+
+// WithAPIRetryWaitTime ...
+func WithAPIRetryWaitTime(apiRetryWaitTime time.Duration) Option {
+	return func(c *config) {
+		c.apiRetryWaitTime = apiRetryWaitTime
+	}
+}
+
+// WithAPIRetryMaxWaitTime ...
+func WithAPIRetryMaxWaitTime(apiRetryMaxWaitTime time.Duration) Option {
+	return func(c *config) {
+		c.apiRetryMaxWaitTime = apiRetryMaxWaitTime
+	}
+}
+
+// WithFallbackCore ...
+func WithFallbackCore(core zapcore.Core) Option {
+	return func(c *config) {
+		c.fallbackCore = core
+	}
+}
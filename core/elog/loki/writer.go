@@ -0,0 +1,253 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+const (
+	// entryChanSize sets the logs size
+	entryChanSize int = 4096
+	// observe interval
+	observeInterval = 5 * time.Second
+	// apiBulkMinSize sets bulk minimal size
+	apiBulkMinSize = 256
+	// defaultFlushInterval 默认刷新间隔
+	defaultFlushInterval = 5 * time.Second
+	// levelLabel 日志级别标签
+	levelLabel = "level"
+	// tenantIDHeader 多租户头
+	tenantIDHeader = "X-Scope-OrgID"
+)
+
+// config is the config for loki writer
+type config struct {
+	encoder             zapcore.Encoder
+	url                 string
+	labels              map[string]string
+	tenantID            string
+	levelEnabler        zapcore.LevelEnabler
+	flushBufferSize     int32
+	flushBufferInterval time.Duration
+	apiBulkSize         int
+	apiTimeout          time.Duration
+	apiRetryCount       int
+	apiRetryWaitTime    time.Duration
+	apiRetryMaxWaitTime time.Duration
+	fallbackCore        zapcore.Core
+}
+
+type entry struct {
+	ent    zapcore.Entry
+	fields []zapcore.Field
+	line   string
+}
+
+// pushRequest is the body of Loki's /loki/api/v1/push in JSON format
+type pushRequest struct {
+	Streams []*stream `json:"streams"`
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// writer buffers entries and pushes them in batches.
+type writer struct {
+	fallbackCore zapcore.Core
+	cli          *resty.Client
+	ch           chan *entry
+	lock         sync.Mutex
+	curBufSize   *int32
+	cancel       context.CancelFunc
+	config
+}
+
+func retryCondition(r *resty.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	code := r.StatusCode()
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// newWriter creates a new loki writer
+func newWriter(c config) (*writer, error) {
+	if c.url == "" {
+		return nil, fmt.Errorf("loki push url is empty")
+	}
+	if c.apiBulkSize >= entryChanSize {
+		c.apiBulkSize = entryChanSize
+	}
+	if c.apiBulkSize < apiBulkMinSize {
+		c.apiBulkSize = apiBulkMinSize
+	}
+	if c.flushBufferInterval <= 0 {
+		c.flushBufferInterval = defaultFlushInterval
+	}
+	w := &writer{
+		config:       c,
+		ch:           make(chan *entry, entryChanSize),
+		curBufSize:   new(int32),
+		fallbackCore: c.fallbackCore,
+	}
+	w.cli = resty.NewWithClient(&http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}).
+		SetDebug(eapp.IsDevelopmentMode()).
+		SetTimeout(c.apiTimeout).
+		SetRetryCount(c.apiRetryCount).
+		SetRetryWaitTime(c.apiRetryWaitTime).
+		SetRetryMaxWaitTime(c.apiRetryMaxWaitTime).
+		AddRetryCondition(retryCondition)
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.sync(ctx)
+	w.observe(ctx)
+	return w, nil
+}
+
+func (w *writer) write(e *entry) (err error) {
+	w.ch <- e
+	atomic.AddInt32(w.curBufSize, int32(len(e.line)))
+	if atomic.LoadInt32(w.curBufSize) >= w.flushBufferSize || len(w.ch) >= cap(w.ch) {
+		err = w.flush()
+		atomic.StoreInt32(w.curBufSize, 0)
+	}
+	return
+}
+
+func (w *writer) flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	entriesChLen := len(w.ch)
+	if entriesChLen == 0 {
+		return nil
+	}
+	var waitedEntries = make([]*entry, 0, entriesChLen)
+	for i := 0; i < entriesChLen; i++ {
+		waitedEntries = append(waitedEntries, <-w.ch)
+	}
+
+	// 同一个stream内的日志需要按时间顺序写入，所以分批串行发送
+	for start := 0; start < len(waitedEntries); start += w.apiBulkSize {
+		end := start + w.apiBulkSize
+		if end > len(waitedEntries) {
+			end = len(waitedEntries)
+		}
+		batch := waitedEntries[start:end]
+		if e := w.push(batch); e != nil {
+			// if error occurs we put logs to fallback logger
+			log.Println("loki push fail", e)
+			w.writeToFallbackLogger(batch)
+		}
+	}
+	return nil
+}
+
+func (w *writer) buildRequest(entries []*entry) *pushRequest {
+	req := &pushRequest{Streams: make([]*stream, 0)}
+	streams := make(map[zapcore.Level]*stream)
+	for _, e := range entries {
+		s, ok := streams[e.ent.Level]
+		if !ok {
+			labels := make(map[string]string, len(w.labels)+1)
+			for k, v := range w.labels {
+				labels[k] = v
+			}
+			labels[levelLabel] = e.ent.Level.String()
+			s = &stream{Stream: labels, Values: make([][2]string, 0)}
+			streams[e.ent.Level] = s
+			req.Streams = append(req.Streams, s)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(e.ent.Time.UnixNano(), 10), e.line})
+	}
+	return req
+}
+
+func (w *writer) push(entries []*entry) error {
+	body, err := json.Marshal(w.buildRequest(entries))
+	if err != nil {
+		return err
+	}
+	req := w.cli.R().SetHeader("Content-Type", "application/json").SetBody(body)
+	if w.tenantID != "" {
+		req.SetHeader(tenantIDHeader, w.tenantID)
+	}
+	res, err := req.Post(w.url)
+	if err != nil {
+		return err
+	}
+	// loki成功时返回204
+	if res.StatusCode() < http.StatusOK || res.StatusCode() >= http.StatusMultipleChoices {
+		return fmt.Errorf("loki push fail, code: %d, body: %s", res.StatusCode(), res.String())
+	}
+	return nil
+}
+
+func (w *writer) writeToFallbackLogger(entries []*entry) {
+	if w.fallbackCore == nil {
+		return
+	}
+	for _, e := range entries {
+		if err := w.fallbackCore.Write(e.ent, e.fields); err != nil {
+			log.Println("fallbackCore write fail", err)
+		}
+	}
+}
+
+func (w *writer) sync(ctx context.Context) {
+	ticker := time.NewTicker(w.flushBufferInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.flush(); err != nil {
+					log.Printf("writer flush fail, %s\n", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (w *writer) observe(ctx context.Context) {
+	ticker := time.NewTicker(observeInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			emetric.LibHandleSummary.Observe(float64(len(w.ch)), "elog", "loki_waited_entries")
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -1,5 +1,9 @@
 package elog
 
+import (
+	"go.uber.org/zap/zapcore"
+)
+
 // Option 可选项
 type Option func(c *Container)
 
@@ -37,3 +41,24 @@ func WithEnableAddCaller(enableAddCaller bool) Option {
 		c.config.EnableAddCaller = enableAddCaller
 	}
 }
+
+// WithRotateHook 设置日志轮转后的回调，参数为备份文件路径，开启压缩时为压缩后的文件路径，可用于上传归档日志
+func WithRotateHook(hook func(filename string)) Option {
+	return func(c *Container) {
+		c.config.rotateHook = hook
+	}
+}
+
+// WithEncoderConfig 设置日志编码配置，配置中的key名称、时间格式会覆盖在其之上
+func WithEncoderConfig(encoderConfig *zapcore.EncoderConfig) Option {
+	return func(c *Container) {
+		c.config.encoderConfig = encoderConfig
+	}
+}
+
+// WithZapCore 设置自定义的zapcore.Core，日志写入该core而不是Writer，日志级别仍由Level控制
+func WithZapCore(core zapcore.Core) Option {
+	return func(c *Container) {
+		c.config.core = core
+	}
+}
@@ -14,6 +14,9 @@ func newRotate(config *Config) io.Writer {
 	rotateLog.MaxBackups = config.MaxBackup
 	rotateLog.Interval = config.RotateInterval
 	rotateLog.LocalTime = true
-	rotateLog.Compress = false
+	rotateLog.Compress = config.EnableCompress
+	rotateLog.MaxTotalSize = config.MaxTotalSize // MB
+	rotateLog.Symlink = config.Symlink
+	rotateLog.OnRotate = config.rotateHook
 	return rotateLog
 }
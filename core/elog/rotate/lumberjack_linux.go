@@ -80,6 +80,9 @@ var _ io.WriteCloser = (*Logger)(nil)
 // time, which may differ from the last time that file was written to.
 //
 // If MaxBackups and MaxAge are both 0, no old log files will be deleted.
+// If MaxTotalSize is set, the oldest backups in the log directory, including
+// the backups of other loggers writing to the same directory, are also deleted
+// until the total size of the directory is within MaxTotalSize megabytes.
 type Logger struct {
 	// Filename is the file to write logs to.  Backup log files will be retained
 	// in the same directory.  It uses <processname>-rotate.log in
@@ -115,6 +118,22 @@ type Logger struct {
 	// The default is not to rotate log files based on time.
 	Interval time.Duration `json:"interval" yaml:"interval"`
 
+	// MaxTotalSize is the maximum size in megabytes of all files in the log
+	// directory. The oldest backups in the directory, whichever logger created
+	// them, are removed once it is exceeded. Active log files are never
+	// removed. The default is not to remove old log files based on size.
+	MaxTotalSize int `json:"maxtotalsize" yaml:"maxtotalsize"`
+
+	// Symlink is the name of a symbolic link which always points at the
+	// current log file. A relative name is resolved against the directory of
+	// Filename. The default is not to create a symbolic link.
+	Symlink string `json:"symlink" yaml:"symlink"`
+
+	// OnRotate is invoked in the mill goroutine for every backup created by
+	// rotation, after compression and removal have been done. The argument is
+	// the full path of the backup, ending with .gz if it has been compressed.
+	OnRotate func(filename string) `json:"-" yaml:"-"`
+
 	size  int64
 	ctime time.Time
 	file  *os.File
@@ -122,6 +141,9 @@ type Logger struct {
 
 	millCh    chan bool
 	startMill sync.Once
+
+	pendingMu sync.Mutex
+	pending   []string
 	queue     chan []byte
 	reopen    chan struct{}
 }
@@ -301,6 +323,7 @@ func (l *Logger) openNew() error {
 		if err := os.Rename(name, newname); err != nil {
 			return fmt.Errorf("can't rename log file: %s", err)
 		}
+		l.addPending(newname)
 
 		// this is a no-op anywhere but linux
 		if err := chown(name, info); err != nil {
@@ -318,6 +341,7 @@ func (l *Logger) openNew() error {
 	l.file = f
 	l.size = 0
 	l.ctime = currentTime()
+	l.link()
 	return nil
 }
 
@@ -368,6 +392,7 @@ func (l *Logger) openExistingOrNew(writeLen int) error {
 	if ct, err := ctime(file); err == nil {
 		l.ctime = ct
 	}
+	l.link()
 
 	return nil
 }
@@ -396,7 +421,9 @@ func (l *Logger) filename() string {
 // files are removed, keeping at most l.MaxBackups files, as long as
 // none of them are older than MaxAge.
 func (l *Logger) millRunOnce() error {
-	if l.MaxBackups == 0 && l.MaxAge == 0 && !l.Compress {
+	pending := l.takePending()
+	if l.MaxBackups == 0 && l.MaxAge == 0 && !l.Compress && l.MaxTotalSize == 0 {
+		l.notifyRotated(pending)
 		return nil
 	}
 
@@ -464,9 +491,135 @@ func (l *Logger) millRunOnce() error {
 		}
 	}
 
+	if l.MaxTotalSize > 0 {
+		if errRemove := l.removeExceeded(); err == nil && errRemove != nil {
+			err = errRemove
+		}
+	}
+
+	l.notifyRotated(pending)
+	return err
+}
+
+// removeExceeded removes the oldest backups in the log directory until the
+// total size of the regular files in the directory is within MaxTotalSize.
+// Backups are recognized by the rotation timestamp at the end of their name,
+// so backups of other loggers in the same directory are counted and removed too.
+func (l *Logger) removeExceeded() error {
+	files, err := ioutil.ReadDir(l.dir())
+	if err != nil {
+		return fmt.Errorf("can't read log file directory: %s", err)
+	}
+	var total int64
+	var backups []logInfo
+	for _, f := range files {
+		// skip directories and the current-file symlink
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		total += f.Size()
+		if t, ok := backupTime(f.Name()); ok {
+			backups = append(backups, logInfo{t, f})
+		}
+	}
+	limit := int64(l.MaxTotalSize) * int64(megabyte)
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].timestamp.Before(backups[j].timestamp)
+	})
+
+	err = nil
+	for _, f := range backups {
+		if total <= limit {
+			break
+		}
+		errRemove := os.Remove(filepath.Join(l.dir(), f.Name()))
+		// another logger may have compressed or removed it in the meantime
+		if errRemove != nil && !os.IsNotExist(errRemove) {
+			if err == nil {
+				err = errRemove
+			}
+			continue
+		}
+		total -= f.Size()
+	}
 	return err
 }
 
+// backupTime extracts the rotation time from a backup name of any logger,
+// which is <filename>.<timestamp> optionally followed by the compress suffix.
+func backupTime(filename string) (time.Time, bool) {
+	name := strings.TrimSuffix(filename, compressSuffix)
+	i := len(name) - len(backupTimeFormat) - 1
+	if i <= 0 || name[i] != '.' {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(backupTimeFormat, name[i+1:])
+	return t, err == nil
+}
+
+// addPending records a backup created by rotation for the OnRotate hook.
+func (l *Logger) addPending(name string) {
+	if l.OnRotate == nil {
+		return
+	}
+	l.pendingMu.Lock()
+	l.pending = append(l.pending, name)
+	l.pendingMu.Unlock()
+}
+
+// takePending returns and clears the backups waiting for the OnRotate hook.
+func (l *Logger) takePending() []string {
+	l.pendingMu.Lock()
+	defer l.pendingMu.Unlock()
+	pending := l.pending
+	l.pending = nil
+	return pending
+}
+
+// notifyRotated invokes OnRotate for the backups which still exist.
+func (l *Logger) notifyRotated(pending []string) {
+	if l.OnRotate == nil {
+		return
+	}
+	for _, name := range pending {
+		if _, err := osStat(name); err == nil {
+			l.OnRotate(name)
+			continue
+		}
+		if _, err := osStat(name + compressSuffix); err == nil {
+			l.OnRotate(name + compressSuffix)
+		}
+	}
+}
+
+// link points Symlink at the current log file. Failures are ignored, as the
+// symbolic link is a convenience which must never prevent logging.
+func (l *Logger) link() {
+	if l.Symlink == "" {
+		return
+	}
+	linkName := l.Symlink
+	if !filepath.IsAbs(linkName) {
+		linkName = filepath.Join(l.dir(), linkName)
+	}
+	target := l.filename()
+	if filepath.Dir(linkName) == filepath.Dir(target) {
+		target = filepath.Base(target)
+	}
+	if current, err := os.Readlink(linkName); err == nil && current == target {
+		return
+	}
+	// create the link aside and rename it, so that it is replaced atomically
+	tmp := linkName + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, linkName); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
 // millRun runs in a goroutine to manage post-rotation compression and removal
 // of old log files.
 func (l *Logger) millRun() {
@@ -0,0 +1,143 @@
+// +build linux
+
+package rotate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fakeCurrentTime = time.Now()
+
+func fakeTime() time.Time {
+	return fakeCurrentTime
+}
+
+func newTestLogger(t *testing.T, l *Logger) (*Logger, chan string, func()) {
+	dir, err := ioutil.TempDir("", "rotate")
+	assert.NoError(t, err)
+	oldMegabyte, oldCurrentTime := megabyte, currentTime
+	megabyte = 1
+	currentTime = fakeTime
+
+	rotated := make(chan string, 16)
+	l.Filename = filepath.Join(dir, "foo.log")
+	l.OnRotate = func(filename string) {
+		rotated <- filename
+	}
+	return l, rotated, func() {
+		_ = l.Close()
+		megabyte, currentTime = oldMegabyte, oldCurrentTime
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func waitRotated(t *testing.T, rotated chan string) string {
+	select {
+	case name := <-rotated:
+		return name
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for rotate hook")
+	}
+	return ""
+}
+
+func writeAndRotate(t *testing.T, l *Logger, rotated chan string, b []byte) string {
+	fakeCurrentTime = fakeCurrentTime.Add(time.Second)
+	_, err := l.Write(b)
+	assert.NoError(t, err)
+	return waitRotated(t, rotated)
+}
+
+func TestMaxTotalSize(t *testing.T) {
+	l, rotated, cleanup := newTestLogger(t, &Logger{MaxSize: 10, MaxTotalSize: 25})
+	defer cleanup()
+
+	b := []byte("0123456789")
+	_, err := l.Write(b)
+	assert.NoError(t, err)
+	first := writeAndRotate(t, l, rotated, b)
+	second := writeAndRotate(t, l, rotated, b)
+
+	// current(10) + second(10) 未超过25，最旧的first被删除
+	_, err = os.Stat(first)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(second)
+	assert.NoError(t, err)
+}
+
+func TestMaxTotalSizePerDirectory(t *testing.T) {
+	l, rotated, cleanup := newTestLogger(t, &Logger{MaxSize: 10, MaxTotalSize: 25})
+	defer cleanup()
+
+	// 同目录下其他日志的备份以及非日志文件也计入总大小
+	dir := filepath.Dir(l.Filename)
+	other := filepath.Join(dir, "bar.log."+fakeCurrentTime.Add(-time.Hour).UTC().Format(backupTimeFormat))
+	assert.NoError(t, ioutil.WriteFile(other, []byte("0123456789"), 0644))
+	plain := filepath.Join(dir, "plain.txt")
+	assert.NoError(t, ioutil.WriteFile(plain, []byte("abc"), 0644))
+
+	b := []byte("0123456789")
+	_, err := l.Write(b)
+	assert.NoError(t, err)
+	backup := writeAndRotate(t, l, rotated, b)
+
+	// current(10) + backup(10) + plain(3) 未超过25，最旧的bar备份被删除
+	_, err = os.Stat(other)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(backup)
+	assert.NoError(t, err)
+	_, err = os.Stat(plain)
+	assert.NoError(t, err)
+}
+
+func TestBackupTime(t *testing.T) {
+	ts := "2021-01-02T03-04-05.000"
+	for _, name := range []string{"foo.log." + ts, "bar." + ts + compressSuffix} {
+		_, ok := backupTime(name)
+		assert.True(t, ok, name)
+	}
+	for _, name := range []string{"foo.log", ts, "foo.log-" + ts, "foo.log.gz"} {
+		_, ok := backupTime(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestCompressHook(t *testing.T) {
+	l, rotated, cleanup := newTestLogger(t, &Logger{MaxSize: 10, Compress: true})
+	defer cleanup()
+
+	b := []byte("0123456789")
+	_, err := l.Write(b)
+	assert.NoError(t, err)
+	name := writeAndRotate(t, l, rotated, b)
+	assert.True(t, strings.HasSuffix(name, compressSuffix), name)
+	_, err = os.Stat(name)
+	assert.NoError(t, err)
+	_, err = os.Stat(strings.TrimSuffix(name, compressSuffix))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSymlink(t *testing.T) {
+	l, rotated, cleanup := newTestLogger(t, &Logger{MaxSize: 10, Symlink: "current.log"})
+	defer cleanup()
+
+	b := []byte("0123456789")
+	_, err := l.Write(b)
+	assert.NoError(t, err)
+	link := filepath.Join(filepath.Dir(l.Filename), "current.log")
+	target, err := os.Readlink(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.log", target)
+
+	writeAndRotate(t, l, rotated, b)
+	content, err := ioutil.ReadFile(link)
+	assert.NoError(t, err)
+	assert.Equal(t, b, content)
+}
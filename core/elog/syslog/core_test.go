@@ -0,0 +1,180 @@
+package syslog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		LevelKey:    "lv",
+		MessageKey:  "msg",
+		EncodeLevel: zapcore.LowercaseLevelEncoder,
+		LineEnding:  zapcore.DefaultLineEnding,
+	})
+}
+
+func TestUDPCore(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer pc.Close()
+
+	core, closeFunc := NewCore(
+		WithEncoder(newTestEncoder()),
+		WithNetwork("udp"),
+		WithAddr(pc.LocalAddr().String()),
+		WithFacility("local1"),
+		WithAppName("test-app"),
+		WithHostname("test-host"),
+		WithLevelEnabler(zapcore.DebugLevel),
+		WithFlushBufferInterval(time.Hour),
+	)
+	logger := zap.New(core).With(zap.String("prefix", "PREFIX"))
+	logger.Warn("hello", zap.Int("count", 1))
+	assert.NoError(t, closeFunc())
+
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	assert.NoError(t, err)
+	msg := string(buf[:n])
+	// local1(17) * 8 + warning(4) = 140
+	assert.True(t, strings.HasPrefix(msg, "<140>1 "), msg)
+	assert.Contains(t, msg, " test-host test-app ")
+	assert.Contains(t, msg, `"msg":"hello"`)
+	assert.Contains(t, msg, `"prefix":"PREFIX"`)
+	assert.Contains(t, msg, `"count":1`)
+	assert.False(t, strings.HasSuffix(msg, "\n"))
+}
+
+func TestTCPCoreOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			lenStr, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(lenStr))
+			frame := make([]byte, size)
+			if _, err := io.ReadFull(r, frame); err != nil {
+				return
+			}
+			received <- string(frame)
+		}
+	}()
+
+	core, closeFunc := NewCore(
+		WithEncoder(newTestEncoder()),
+		WithNetwork("tcp"),
+		WithAddr(ln.Addr().String()),
+		WithLevelEnabler(zapcore.InfoLevel),
+		WithFlushBufferInterval(time.Hour),
+	)
+	logger := zap.New(core)
+	logger.Info("first")
+	logger.Error("second")
+	assert.NoError(t, closeFunc())
+
+	for _, want := range []string{`"msg":"first"`, `"msg":"second"`} {
+		select {
+		case msg := <-received:
+			assert.Contains(t, msg, want)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timeout waiting for %s", want)
+		}
+	}
+}
+
+type recordCore struct {
+	zapcore.LevelEnabler
+	entries []zapcore.Entry
+}
+
+func (r *recordCore) With([]zapcore.Field) zapcore.Core { return r }
+func (r *recordCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, r)
+}
+func (r *recordCore) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	r.entries = append(r.entries, ent)
+	return nil
+}
+func (r *recordCore) Sync() error { return nil }
+
+func TestFallbackCore(t *testing.T) {
+	// 没有服务监听的tcp端口，发送失败后写入fallback
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	fallback := &recordCore{LevelEnabler: zapcore.DebugLevel}
+	core, closeFunc := NewCore(
+		WithEncoder(newTestEncoder()),
+		WithNetwork("tcp"),
+		WithAddr(addr),
+		WithTimeout(time.Second),
+		WithLevelEnabler(zapcore.InfoLevel),
+		WithFlushBufferInterval(time.Hour),
+		WithFallbackCore(fallback),
+	)
+	zap.New(core).Info("lost")
+	assert.NoError(t, closeFunc())
+	assert.Len(t, fallback.entries, 1)
+	assert.Equal(t, "lost", fallback.entries[0].Message)
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	fallback := &recordCore{LevelEnabler: zapcore.DebugLevel}
+	w, err := newWriter(config{
+		encoder:         newTestEncoder(),
+		network:         "tcp",
+		addr:            "127.0.0.1:1",
+		flushBufferSize: 1 << 20,
+		fallbackCore:    fallback,
+	})
+	assert.NoError(t, err)
+	defer w.cancel()
+	dials := 0
+	w.dial = func(network, addr string, timeout time.Duration) (net.Conn, error) {
+		dials++
+		return nil, errors.New("connection refused")
+	}
+
+	// 连接失败后进入退避，之后的日志不再连接，直接写入fallback
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, w.write(&message{ent: zapcore.Entry{Message: "lost"}, body: []byte("lost")}))
+	}
+	assert.NoError(t, w.flush())
+	assert.Equal(t, 1, dials)
+	assert.Len(t, fallback.entries, 100)
+	assert.Equal(t, minReconnectBackoff, w.backoff)
+
+	// 退避结束后重新连接，再次失败时退避时间翻倍
+	w.retryAt = time.Now().Add(time.Minute)
+	assert.ErrorIs(t, w.send([]byte("x")), errDisconnected)
+	w.retryAt = time.Time{}
+	assert.Error(t, w.send([]byte("x")))
+	assert.Equal(t, 2, dials)
+	assert.Equal(t, 2*minReconnectBackoff, w.backoff)
+}
@@ -0,0 +1,87 @@
+package syslog
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Option 可选项
+type Option func(c *config)
+
+// WithEncoder ...
+func WithEncoder(enc zapcore.Encoder) Option {
+	return func(c *config) {
+		c.encoder = enc
+	}
+}
+
+// WithNetwork 设置网络类型，可选[udp|tcp|unix|unixgram]
+func WithNetwork(network string) Option {
+	return func(c *config) {
+		c.network = network
+	}
+}
+
+// WithAddr 设置syslog服务地址，unix类型为socket文件路径
+func WithAddr(addr string) Option {
+	return func(c *config) {
+		c.addr = addr
+	}
+}
+
+// WithFacility 设置facility，例如local0
+func WithFacility(facility string) Option {
+	return func(c *config) {
+		c.facility = facility
+	}
+}
+
+// WithAppName 设置RFC 5424中的APP-NAME
+func WithAppName(appName string) Option {
+	return func(c *config) {
+		c.appName = appName
+	}
+}
+
+// WithHostname 设置RFC 5424中的HOSTNAME
+func WithHostname(hostname string) Option {
+	return func(c *config) {
+		c.hostname = hostname
+	}
+}
+
+// WithLevelEnabler ...
+func WithLevelEnabler(lv zapcore.LevelEnabler) Option {
+	return func(c *config) {
+		c.levelEnabler = lv
+	}
+}
+
+// WithFlushBufferSize ...
+func WithFlushBufferSize(flushBufferSize int) Option {
+	return func(c *config) {
+		c.flushBufferSize = int32(flushBufferSize)
+	}
+}
+
+// WithFlushBufferInterval ...
+func WithFlushBufferInterval(flushBufferInterval time.Duration) Option {
+	return func(c *config) {
+		c.flushBufferInterval = flushBufferInterval
+	}
+}
+
+// WithTimeout 设置连接以及写入超时
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithFallbackCore ...
+func WithFallbackCore(core zapcore.Core) Option {
+	return func(c *config) {
+		c.fallbackCore = core
+	}
+}
@@ -0,0 +1,324 @@
+package syslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+const (
+	// entryChanSize sets the logs size
+	entryChanSize int = 4096
+	// observe interval
+	observeInterval = 5 * time.Second
+	// rfc5424TimeFormat RFC 5424 TIMESTAMP，精确到微秒
+	rfc5424TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+	// nilValue RFC 5424中空字段的值
+	nilValue = "-"
+	// defaultFlushInterval 默认刷新间隔
+	defaultFlushInterval = 5 * time.Second
+	// minReconnectBackoff、maxReconnectBackoff 连接失败后重连的退避时间
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// errDisconnected 重连退避期间不再连接，日志直接写入fallbackCore
+var errDisconnected = errors.New("syslog server disconnected")
+
+var facilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+// config is the config for syslog writer
+type config struct {
+	encoder             zapcore.Encoder
+	network             string
+	addr                string
+	facility            string
+	appName             string
+	hostname            string
+	levelEnabler        zapcore.LevelEnabler
+	flushBufferSize     int32
+	flushBufferInterval time.Duration
+	timeout             time.Duration
+	fallbackCore        zapcore.Core
+}
+
+type message struct {
+	ent    zapcore.Entry
+	fields []zapcore.Field
+	body   []byte
+}
+
+// writer buffers messages and sends them to syslog server in order.
+type writer struct {
+	fallbackCore zapcore.Core
+	ch           chan *message
+	lock         sync.Mutex
+	connLock     sync.Mutex
+	conn         net.Conn
+	dial         func(network, addr string, timeout time.Duration) (net.Conn, error)
+	backoff      time.Duration // 当前的重连退避时间，连接成功后清零
+	retryAt      time.Time     // 退避结束的时间
+	facility     int
+	procID       string
+	curBufSize   *int32
+	cancel       context.CancelFunc
+	config
+}
+
+// newWriter creates a new syslog writer
+func newWriter(c config) (*writer, error) {
+	if c.network == "" {
+		c.network = "udp"
+	}
+	switch c.network {
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("unsupported syslog network %q", c.network)
+	}
+	facility, ok := facilities[c.facility]
+	if !ok {
+		if c.facility != "" {
+			return nil, fmt.Errorf("unsupported syslog facility %q", c.facility)
+		}
+		facility = facilities["local0"]
+	}
+	if c.flushBufferInterval <= 0 {
+		c.flushBufferInterval = defaultFlushInterval
+	}
+	if c.hostname == "" {
+		c.hostname, _ = os.Hostname()
+	}
+	if c.appName == "" {
+		c.appName = nilValue
+	}
+	if c.hostname == "" {
+		c.hostname = nilValue
+	}
+	w := &writer{
+		config:       c,
+		ch:           make(chan *message, entryChanSize),
+		curBufSize:   new(int32),
+		facility:     facility,
+		procID:       strconv.Itoa(os.Getpid()),
+		fallbackCore: c.fallbackCore,
+		dial:         net.DialTimeout,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.sync(ctx)
+	w.observe(ctx)
+	return w, nil
+}
+
+// severity maps zap level to syslog severity
+func severity(lv zapcore.Level) int {
+	switch lv {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel:
+		return 2
+	case zapcore.PanicLevel:
+		return 1
+	case zapcore.FatalLevel:
+		return 0
+	default:
+		return 6
+	}
+}
+
+// frame builds an RFC 5424 message:
+// <PRI>VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA SP MSG
+func (w *writer) frame(m *message) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(w.facility*8 + severity(m.ent.Level)))
+	buf.WriteString(">1 ")
+	buf.WriteString(m.ent.Time.Format(rfc5424TimeFormat))
+	buf.WriteByte(' ')
+	buf.WriteString(w.hostname)
+	buf.WriteByte(' ')
+	buf.WriteString(w.appName)
+	buf.WriteByte(' ')
+	buf.WriteString(w.procID)
+	buf.WriteString(" - - ")
+	buf.Write(bytes.TrimRight(m.body, "\r\n"))
+
+	// stream类型的连接需要使用RFC 6587的octet counting分帧
+	if !w.isDatagram() {
+		return append([]byte(strconv.Itoa(buf.Len())+" "), buf.Bytes()...)
+	}
+	return buf.Bytes()
+}
+
+func (w *writer) isDatagram() bool {
+	switch w.network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	}
+	return false
+}
+
+func (w *writer) write(m *message) (err error) {
+	w.ch <- m
+	atomic.AddInt32(w.curBufSize, int32(len(m.body)))
+	if atomic.LoadInt32(w.curBufSize) >= w.flushBufferSize || len(w.ch) >= cap(w.ch) {
+		err = w.flush()
+		atomic.StoreInt32(w.curBufSize, 0)
+	}
+	return
+}
+
+func (w *writer) flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	entriesChLen := len(w.ch)
+	if entriesChLen == 0 {
+		return nil
+	}
+
+	// syslog需要保证顺序，所以这里串行发送
+	for i := 0; i < entriesChLen; i++ {
+		m := <-w.ch
+		if e := w.send(w.frame(m)); e != nil {
+			// if error occurs we put logs to fallback logger
+			w.writeToFallbackLogger(m)
+		}
+	}
+	return nil
+}
+
+// send writes one frame, reconnecting once if the connection is broken
+func (w *writer) send(frame []byte) error {
+	w.connLock.Lock()
+	defer w.connLock.Unlock()
+
+	var err error
+	for i := 0; i < 2; i++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				return err
+			}
+		}
+		if w.timeout > 0 {
+			_ = w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+		}
+		if _, err = w.conn.Write(frame); err == nil {
+			return nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+// connect 连接失败后按指数退避，退避期间直接返回errDisconnected，避免服务端不可用时每条日志都等待连接超时
+func (w *writer) connect() error {
+	now := time.Now()
+	if now.Before(w.retryAt) {
+		return errDisconnected
+	}
+	conn, err := w.dial(w.network, w.addr, w.timeout)
+	if err != nil {
+		w.backoff *= 2
+		if w.backoff < minReconnectBackoff {
+			w.backoff = minReconnectBackoff
+		}
+		if w.backoff > maxReconnectBackoff {
+			w.backoff = maxReconnectBackoff
+		}
+		w.retryAt = now.Add(w.backoff)
+		return err
+	}
+	w.conn = conn
+	w.backoff = 0
+	return nil
+}
+
+func (w *writer) close() {
+	w.connLock.Lock()
+	defer w.connLock.Unlock()
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *writer) writeToFallbackLogger(m *message) {
+	if w.fallbackCore == nil {
+		return
+	}
+	if e := w.fallbackCore.Write(m.ent, m.fields); e != nil {
+		log.Println("fallbackCore write fail", e)
+	}
+}
+
+func (w *writer) sync(ctx context.Context) {
+	ticker := time.NewTicker(w.flushBufferInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.flush(); err != nil {
+					log.Printf("writer flush fail, %s\n", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (w *writer) observe(ctx context.Context) {
+	ticker := time.NewTicker(observeInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			emetric.LibHandleSummary.Observe(float64(len(w.ch)), "elog", "syslog_waited_entries")
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,103 @@
+package elog
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WriterBuilder 根据日志配置构造写入日志的zapcore.Core
+// 第三方可以实现该接口，并通过 RegisterWriter 注册，在配置里通过 writer = "名称" 使用
+type WriterBuilder interface {
+	// Build 构造zapcore.Core，key为日志配置的key，可以用来读取第三方Writer自定义的配置
+	Build(key string, config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc)
+	// Scheme Writer名称
+	Scheme() string
+}
+
+var (
+	writersMu sync.RWMutex
+	// 内置Writer在变量初始化阶段注册，保证早于api.go中init构造默认日志
+	writers = map[string]WriterBuilder{
+		writerRotateFile: &fileWriterBuilder{},
+		writerAliSLS:     &aliWriterBuilder{},
+		writerStderr:     &stderrWriterBuilder{},
+		writerSyslog:     &syslogWriterBuilder{},
+		writerLoki:       &lokiWriterBuilder{},
+	}
+)
+
+// RegisterWriter 注册Writer，如果名称相同，后注册的会覆盖先注册的
+func RegisterWriter(builder WriterBuilder) {
+	writersMu.Lock()
+	defer writersMu.Unlock()
+	writers[builder.Scheme()] = builder
+}
+
+// WriterProvider 根据名称获取已注册的Writer，不存在返回nil
+func WriterProvider(scheme string) WriterBuilder {
+	writersMu.RLock()
+	defer writersMu.RUnlock()
+	return writers[scheme]
+}
+
+type fileWriterBuilder struct{}
+
+// Build ...
+func (*fileWriterBuilder) Build(key string, config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	return newRotateFileCore(config, lv)
+}
+
+// Scheme ...
+func (*fileWriterBuilder) Scheme() string {
+	return writerRotateFile
+}
+
+type aliWriterBuilder struct{}
+
+// Build ...
+func (*aliWriterBuilder) Build(key string, config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	return newAliCore(config, lv)
+}
+
+// Scheme ...
+func (*aliWriterBuilder) Scheme() string {
+	return writerAliSLS
+}
+
+type stderrWriterBuilder struct{}
+
+// Build ...
+func (*stderrWriterBuilder) Build(key string, config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	return newStderrCore(config, lv)
+}
+
+// Scheme ...
+func (*stderrWriterBuilder) Scheme() string {
+	return writerStderr
+}
+
+type syslogWriterBuilder struct{}
+
+// Build ...
+func (*syslogWriterBuilder) Build(key string, config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	return newSyslogCore(config, lv)
+}
+
+// Scheme ...
+func (*syslogWriterBuilder) Scheme() string {
+	return writerSyslog
+}
+
+type lokiWriterBuilder struct{}
+
+// Build ...
+func (*lokiWriterBuilder) Build(key string, config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	return newLokiCore(config, lv)
+}
+
+// Scheme ...
+func (*lokiWriterBuilder) Scheme() string {
+	return writerLoki
+}
@@ -0,0 +1,51 @@
+package elog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type memoryWriterBuilder struct {
+	key     string
+	entries []zapcore.Entry
+}
+
+func (b *memoryWriterBuilder) Build(key string, config *Config, lv zap.AtomicLevel) (zapcore.Core, CloseFunc) {
+	b.key = key
+	return zapcore.NewCore(zapcore.NewJSONEncoder(*config.EncoderConfig()), zapcore.AddSync(nopWriter{}), lv), noopCloseFunc
+}
+
+func (b *memoryWriterBuilder) Scheme() string {
+	return "memory"
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestRegisterWriter(t *testing.T) {
+	builder := &memoryWriterBuilder{}
+	RegisterWriter(builder)
+	assert.Equal(t, builder, WriterProvider("memory"))
+	for _, scheme := range []string{writerRotateFile, writerAliSLS, writerStderr, writerSyslog, writerLoki} {
+		assert.NotNil(t, WriterProvider(scheme), scheme)
+	}
+
+	c := DefaultContainer()
+	c.name = "logger.memory"
+	c.config.Writer = "memory"
+	logger := c.Build()
+	logger.Info("hello")
+	assert.Equal(t, "logger.memory", builder.key)
+}
+
+func TestUnsupportedWriter(t *testing.T) {
+	c := DefaultContainer()
+	c.config.Writer = "unknown"
+	assert.Panics(t, func() {
+		c.Build()
+	})
+}
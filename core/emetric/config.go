@@ -0,0 +1,84 @@
+package emetric
+
+import (
+	"github.com/gotomicro/ego/core/eapp"
+)
+
+// Config 监控配置，配置在metric下，只影响框架内置的指标
+type Config struct {
+	Namespace      string               // 指标前缀，默认ego
+	ConstLabels    map[string]string    // 内置指标的固定label
+	AppLabels      []string             // 从应用信息中读取的固定label，可选[app|mode|region|zone|hostname]
+	Buckets        map[string][]float64 // 按指标名(不带前缀)配置histogram的bucket，例如server_handle_seconds
+	DisableMetrics []string             // 关闭的内置指标(不带前缀)，例如lib_handle_stats
+	LabelLimit     int                  // 内置指标的label组合数上限，超过后合并为__other__，0使用默认值2000，小于0不限制
+	LabelLimits    map[string]int       // 按指标名(不带前缀)配置label组合数上限，优先于LabelLimit
+}
+
+// DefaultConfig ...
+func DefaultConfig() *Config {
+	return &Config{
+		Namespace:      DefaultNamespace,
+		ConstLabels:    make(map[string]string),
+		AppLabels:      make([]string, 0),
+		Buckets:        make(map[string][]float64),
+		DisableMetrics: make([]string, 0),
+		LabelLimits:    make(map[string]int),
+	}
+}
+
+// constLabels 合并配置的固定label，跳过与指标自身label同名的key
+func (config *Config) constLabels(base map[string]string, labels []string) map[string]string {
+	merged := make(map[string]string)
+	for _, name := range config.AppLabels {
+		if v, ok := appLabelValue(name); ok {
+			merged[name] = v
+		}
+	}
+	for k, v := range config.ConstLabels {
+		merged[k] = v
+	}
+	for k, v := range base {
+		merged[k] = v
+	}
+	for _, label := range labels {
+		delete(merged, label)
+	}
+	return merged
+}
+
+func (config *Config) disabled(name string) bool {
+	for _, n := range config.DisableMetrics {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// labelLimit 指标的label组合数上限，base为指标自身的默认值
+func (config *Config) labelLimit(name string, base int) int {
+	if limit, ok := config.LabelLimits[name]; ok {
+		return limit
+	}
+	if config.LabelLimit != 0 {
+		return config.LabelLimit
+	}
+	return base
+}
+
+func appLabelValue(name string) (string, bool) {
+	switch name {
+	case "app":
+		return eapp.Name(), true
+	case "mode":
+		return eapp.AppMode(), true
+	case "region":
+		return eapp.AppRegion(), true
+	case "zone":
+		return eapp.AppZone(), true
+	case "hostname":
+		return eapp.HostName(), true
+	}
+	return "", false
+}
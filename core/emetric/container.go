@@ -0,0 +1,80 @@
+package emetric
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container 容器
+type Container struct {
+	config     *Config
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+	err        error
+}
+
+// DefaultContainer 默认容器
+func DefaultContainer() *Container {
+	return &Container{
+		config:     DefaultConfig(),
+		registerer: DefaultRegisterer,
+		gatherer:   DefaultGatherer,
+	}
+}
+
+// Load 加载配置key
+func Load(key string) *Container {
+	c := DefaultContainer()
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.err = fmt.Errorf("unmarshal key %s fail, %w", key, err)
+	}
+	return c
+}
+
+// WithRegisterer 将内置指标注册到指定的Registerer，之后通过Opts.Build构造的指标也注册到该Registerer。
+// 如果同时实现了prometheus.Gatherer(例如*prometheus.Registry)，governor的/metrics也从中读取指标
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(c *Container) {
+		c.registerer = registerer
+		if gatherer, ok := registerer.(prometheus.Gatherer); ok {
+			c.gatherer = gatherer
+		}
+	}
+}
+
+// WithGatherer 设置governor的/metrics读取指标的Gatherer
+func WithGatherer(gatherer prometheus.Gatherer) Option {
+	return func(c *Container) {
+		c.gatherer = gatherer
+	}
+}
+
+// Build 按配置重新构造内置指标，一般在ego.New的初始化阶段调用。
+// 内置指标的Inc、Observe等方法与重新构造互斥，直接使用内嵌的prometheus指标时需要在Build之后获取
+func (c *Container) Build(options ...Option) error {
+	for _, option := range options {
+		option(c)
+	}
+	if c.err != nil {
+		return c.err
+	}
+	for _, b := range builtins {
+		DefaultRegisterer.Unregister(b.collector())
+		b.rebuild(c.config)
+		if c.config.disabled(b.name()) {
+			continue
+		}
+		if err := c.registerer.Register(b.collector()); err != nil {
+			return fmt.Errorf("register metric %s fail, %w", b.name(), err)
+		}
+	}
+	DefaultRegisterer, DefaultGatherer = c.registerer, c.gatherer
+	setBuildInfo()
+	return nil
+}
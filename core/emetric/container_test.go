@@ -0,0 +1,100 @@
+package emetric
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+func TestContainerBuild(t *testing.T) {
+	require.NoError(t, econf.LoadFromReader(bytes.NewBufferString(`
+[metric]
+namespace = "test"
+appLabels = ["region", "name"]
+disableMetrics = ["lib_handle_stats"]
+[metric.constLabels]
+cluster = "c1"
+name = "ignored"
+[metric.buckets]
+server_handle_seconds = [0.1, 1.0]
+`), toml.Unmarshal))
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, Load("metric").Build(WithRegisterer(registry)))
+	defer func() {
+		require.NoError(t, DefaultContainer().Build(WithRegisterer(prometheus.DefaultRegisterer)))
+	}()
+	assert.Equal(t, registry, DefaultRegisterer)
+	assert.Equal(t, registry, DefaultGatherer)
+	// 再次构造时沿用之前设置的Registerer
+	require.NoError(t, Load("metric").Build())
+	assert.Equal(t, registry, DefaultRegisterer)
+	assert.Equal(t, registry, DefaultGatherer)
+
+	ServerHandleHistogram.Observe(0.5, TypeHTTP, "GET./hello", "127.0.0.1")
+	LibHandleSummary.Observe(1, "ali", "ok")
+	custom := NewCounterVec("custom_total", []string{"code"})
+	custom.Inc("ok")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	names := make([]string, 0)
+	for _, mf := range families {
+		names = append(names, mf.GetName())
+		if mf.GetName() != "test_server_handle_seconds" {
+			continue
+		}
+		metric := mf.GetMetric()[0]
+		labels := make(map[string]string)
+		for _, l := range metric.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		assert.Equal(t, "c1", labels["cluster"])
+		assert.Contains(t, labels, "region")
+		// name不是可选的应用label，这里来自constLabels
+		assert.Equal(t, "ignored", labels["name"])
+		require.Len(t, metric.GetHistogram().GetBucket(), 2)
+		assert.Equal(t, 0.1, metric.GetHistogram().GetBucket()[0].GetUpperBound())
+	}
+	assert.Contains(t, names, "test_server_handle_seconds")
+	assert.Contains(t, names, "test_build_info")
+	assert.Contains(t, names, "ego_custom_total")
+	for _, name := range names {
+		assert.False(t, strings.HasSuffix(name, "lib_handle_stats"))
+	}
+
+	// build_info的label中已有name、region，不重复添加
+	assert.Equal(t, 1, testutil.CollectAndCount(BuildInfoGauge))
+}
+
+func TestContainerBuildConcurrent(t *testing.T) {
+	// 上限为1，记录时不断超限，超限计数与rebuild并发
+	container := DefaultContainer()
+	container.config.LabelLimit = 1
+	defer func() {
+		require.NoError(t, DefaultContainer().Build())
+	}()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			ServerHandleCounter.Inc(TypeHTTP, "GET./hello"+strconv.Itoa(i), "127.0.0.1", "OK")
+			ServerHandleHistogram.Observe(0.5, TypeHTTP, "GET./hello", "127.0.0.1")
+			LibHandleSummary.Observe(1, "ali", "ok")
+			ClientConnGauge.Set(1, TypeHTTP, "test", "127.0.0.1", "open")
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, container.Build())
+	}
+	<-done
+}
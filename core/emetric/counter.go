@@ -1,33 +1,44 @@
 package emetric
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // CounterVecOpts ...
 type CounterVecOpts struct {
-	Namespace string
-	Subsystem string
-	Name      string
-	Help      string
-	Labels    []string
+	Namespace   string
+	Subsystem   string
+	Name        string
+	Help        string
+	Labels      []string
+	ConstLabels map[string]string
+	LabelLimit  int // label组合数上限，超过后新的组合合并为__other__，小于等于0不限制
 }
 
 // Build ...
 func (opts CounterVecOpts) Build() *CounterVec {
-	vec := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: opts.Namespace,
-			Subsystem: opts.Subsystem,
-			Name:      opts.Name,
-			Help:      opts.Help,
-		}, opts.Labels)
-	prometheus.MustRegister(vec)
+	vec := opts.newVec()
+	DefaultRegisterer.MustRegister(vec)
 	return &CounterVec{
 		CounterVec: vec,
+		opts:       opts,
+		limiter:    newLabelLimiter(opts.Name, opts.LabelLimit, len(opts.Labels)),
 	}
 }
 
+func (opts CounterVecOpts) newVec() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: opts.ConstLabels,
+		}, opts.Labels)
+}
+
 // NewCounterVec ...
 func NewCounterVec(name string, labels []string) *CounterVec {
 	return CounterVecOpts{
@@ -41,14 +52,57 @@ func NewCounterVec(name string, labels []string) *CounterVec {
 // CounterVec ...
 type CounterVec struct {
 	*prometheus.CounterVec
+	mu      sync.RWMutex // rebuild时替换vec、limiter
+	opts    CounterVecOpts
+	limiter *labelLimiter
 }
 
 // Inc ...
 func (counter *CounterVec) Inc(labels ...string) {
-	counter.WithLabelValues(labels...).Inc()
+	counter.with(labels).Inc()
 }
 
 // Add ...
 func (counter *CounterVec) Add(v float64, labels ...string) {
-	counter.WithLabelValues(labels...).Add(v)
+	counter.with(labels).Add(v)
+}
+
+func (counter *CounterVec) name() string {
+	return counter.opts.Name
+}
+
+func (counter *CounterVec) collector() prometheus.Collector {
+	counter.mu.RLock()
+	defer counter.mu.RUnlock()
+	return counter.CounterVec
+}
+
+// with 返回限制label组合后的指标，与rebuild互斥
+func (counter *CounterVec) with(labels []string) prometheus.Counter {
+	counter.mu.RLock()
+	labels, overflow := counter.limiter.limit(labels)
+	metric := counter.CounterVec.WithLabelValues(labels...)
+	counter.mu.RUnlock()
+	if overflow {
+		countOverflow(counter.name())
+	}
+	return metric
+}
+
+// incUnlimited 不经过label组合数限制，与rebuild互斥
+func (counter *CounterVec) incUnlimited(labels ...string) {
+	counter.mu.RLock()
+	defer counter.mu.RUnlock()
+	counter.CounterVec.WithLabelValues(labels...).Inc()
+}
+
+func (counter *CounterVec) rebuild(config *Config) {
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	opts := counter.opts
+	opts.Namespace = config.Namespace
+	opts.ConstLabels = config.constLabels(opts.ConstLabels, opts.Labels)
+	opts.LabelLimit = config.labelLimit(opts.Name, opts.LabelLimit)
+	counter.CounterVec = opts.newVec()
+	counter.limiter = newLabelLimiter(opts.Name, opts.LabelLimit, len(opts.Labels))
 }
@@ -0,0 +1,111 @@
+package epush
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.emetric.epush"
+
+// Component 将指标推送到Pushgateway，用于ejob等在Prometheus抓取之前就退出的短时任务
+type Component struct {
+	name     string
+	config   *Config
+	logger   *elog.Component
+	pusher   *push.Pusher
+	stop     chan struct{}
+	done     chan struct{}
+	started  int32
+	stopOnce sync.Once
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) *Component {
+	// 每次推送时读取emetric.DefaultGatherer，emetric重新Build之后也能推送最新的registry
+	gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return emetric.DefaultGatherer.Gather()
+	})
+	pusher := push.New(config.Addr, config.Job).
+		Gatherer(gatherer).
+		Client(&http.Client{Timeout: config.Timeout})
+	for k, v := range config.Grouping {
+		pusher = pusher.Grouping(k, v)
+	}
+	if config.Username != "" {
+		pusher = pusher.BasicAuth(config.Username, config.Password)
+	}
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		pusher: pusher,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Name 配置名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// PackageName 包名
+func (c *Component) PackageName() string {
+	return PackageName
+}
+
+// Start 按间隔在后台推送，不阻塞
+func (c *Component) Start() {
+	if atomic.CompareAndSwapInt32(&c.started, 0, 1) {
+		go c.loop()
+	}
+}
+
+// Push 立即推送一次，替换Pushgateway中同一分组的全部指标
+func (c *Component) Push() error {
+	return c.pusher.Push()
+}
+
+// Stop 停止定时推送，并在退出前推送最后一次，可以直接传给ego.WithAfterStopClean
+func (c *Component) Stop() error {
+	var err error
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		if atomic.LoadInt32(&c.started) == 1 {
+			<-c.done
+		}
+		if err = c.Push(); err != nil {
+			c.logger.Error("push metrics on stop", elog.FieldErr(err), elog.FieldAddr(c.config.Addr))
+		}
+	})
+	return err
+}
+
+func (c *Component) loop() {
+	defer close(c.done)
+	if c.config.Interval <= 0 {
+		<-c.stop
+		return
+	}
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Push(); err != nil {
+				c.logger.Error("push metrics", elog.FieldErr(err), elog.FieldAddr(c.config.Addr))
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package epush
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+type pushRecord struct {
+	method string
+	path   string
+	body   []byte
+}
+
+type gateway struct {
+	*httptest.Server
+	mu      sync.Mutex
+	records []pushRecord
+}
+
+func newGateway() *gateway {
+	g := &gateway{}
+	g.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		g.mu.Lock()
+		g.records = append(g.records, pushRecord{method: r.Method, path: r.URL.Path, body: body})
+		g.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	return g
+}
+
+func (g *gateway) pushes() []pushRecord {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]pushRecord(nil), g.records...)
+}
+
+func TestPushInterval(t *testing.T) {
+	g := newGateway()
+	defer g.Close()
+	counter := emetric.NewCounterVec("epush_interval_total", []string{"code"})
+	counter.Inc("ok")
+
+	comp := DefaultContainer().Build(
+		WithAddr(g.URL),
+		WithJob("svc"),
+		WithGrouping("job_name", "sync"),
+		WithGrouping("empty", ""),
+		WithInterval(10*time.Millisecond),
+	)
+	comp.Start()
+	assert.Eventually(t, func() bool {
+		return len(g.pushes()) >= 2
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, comp.Stop())
+	// 重复Stop不会再次推送
+	count := len(g.pushes())
+	require.NoError(t, comp.Stop())
+	assert.Len(t, g.pushes(), count)
+
+	for _, record := range g.pushes() {
+		assert.Equal(t, http.MethodPut, record.method)
+		assert.Equal(t, "/metrics/job/svc/job_name/sync", record.path)
+		assert.True(t, bytes.Contains(record.body, []byte("epush_interval_total")))
+	}
+}
+
+func TestPushOnStop(t *testing.T) {
+	g := newGateway()
+	defer g.Close()
+
+	comp := DefaultContainer().Build(WithAddr(g.URL), WithJob("svc"), WithInterval(0))
+	comp.Start()
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, g.pushes(), 0)
+
+	require.NoError(t, comp.Stop())
+	pushes := g.pushes()
+	require.Len(t, pushes, 1)
+	assert.Equal(t, "/metrics/job/svc", pushes[0].path)
+}
+
+func TestPushError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	comp := DefaultContainer().Build(WithAddr(srv.URL), WithInterval(0))
+	assert.Error(t, comp.Push())
+	assert.Error(t, comp.Stop())
+}
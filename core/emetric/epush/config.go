@@ -0,0 +1,29 @@
+package epush
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 推送配置，配置在metric.push下
+type Config struct {
+	Addr     string            // Pushgateway地址，例如 http://127.0.0.1:9091
+	Job      string            // Pushgateway的job label，默认应用名
+	Grouping map[string]string // 额外的分组label
+	Interval time.Duration     // 推送间隔，默认15s，小于等于0时只在退出时推送一次
+	Timeout  time.Duration     // 单次推送超时时间，默认3s
+	Username string            // basic auth用户名，为空时不认证
+	Password string            // basic auth密码
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Job:      eapp.Name(),
+		Grouping: make(map[string]string),
+		Interval: xtime.Duration("15s"),
+		Timeout:  xtime.Duration("3s"),
+	}
+}
@@ -0,0 +1,37 @@
+package epush
+
+import "time"
+
+// Option 选项
+type Option func(c *Container)
+
+// WithAddr 设置Pushgateway地址
+func WithAddr(addr string) Option {
+	return func(c *Container) {
+		c.config.Addr = addr
+	}
+}
+
+// WithJob 设置Pushgateway的job label
+func WithJob(job string) Option {
+	return func(c *Container) {
+		c.config.Job = job
+	}
+}
+
+// WithGrouping 添加分组label，value为空时忽略
+func WithGrouping(name, value string) Option {
+	return func(c *Container) {
+		if value == "" {
+			return
+		}
+		c.config.Grouping[name] = value
+	}
+}
+
+// WithInterval 设置推送间隔，小于等于0时只在退出时推送一次
+func WithInterval(interval time.Duration) Option {
+	return func(c *Container) {
+		c.config.Interval = interval
+	}
+}
@@ -1,36 +1,52 @@
 package emetric
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // GaugeVecOpts ...
 type GaugeVecOpts struct {
-	Namespace string
-	Subsystem string
-	Name      string
-	Help      string
-	Labels    []string
+	Namespace   string
+	Subsystem   string
+	Name        string
+	Help        string
+	Labels      []string
+	ConstLabels map[string]string
+	LabelLimit  int // label组合数上限，超过后新的组合合并为__other__，小于等于0不限制
 }
 
 // GaugeVec ...
 type GaugeVec struct {
 	*prometheus.GaugeVec
+	mu      sync.RWMutex // rebuild时替换vec、limiter
+	opts    GaugeVecOpts
+	limiter *labelLimiter
 }
 
 // Build ...
 func (opts GaugeVecOpts) Build() *GaugeVec {
-	vec := prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: opts.Namespace,
-			Subsystem: opts.Subsystem,
-			Name:      opts.Name,
-			Help:      opts.Help,
-		}, opts.Labels)
-	prometheus.MustRegister(vec)
+	vec := opts.newVec()
+	DefaultRegisterer.MustRegister(vec)
 	return &GaugeVec{
 		GaugeVec: vec,
+		opts:     opts,
+		limiter:  newLabelLimiter(opts.Name, opts.LabelLimit, len(opts.Labels)),
 	}
 }
 
+func (opts GaugeVecOpts) newVec() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: opts.ConstLabels,
+		}, opts.Labels)
+}
+
 // NewGaugeVec ...
 func NewGaugeVec(name string, labels []string) *GaugeVec {
 	return GaugeVecOpts{
@@ -43,15 +59,48 @@ func NewGaugeVec(name string, labels []string) *GaugeVec {
 
 // Inc ...
 func (gv *GaugeVec) Inc(labels ...string) {
-	gv.WithLabelValues(labels...).Inc()
+	gv.with(labels).Inc()
 }
 
 // Add ...
 func (gv *GaugeVec) Add(v float64, labels ...string) {
-	gv.WithLabelValues(labels...).Add(v)
+	gv.with(labels).Add(v)
 }
 
 // Set ...
 func (gv *GaugeVec) Set(v float64, labels ...string) {
-	gv.WithLabelValues(labels...).Set(v)
+	gv.with(labels).Set(v)
+}
+
+func (gv *GaugeVec) name() string {
+	return gv.opts.Name
+}
+
+func (gv *GaugeVec) collector() prometheus.Collector {
+	gv.mu.RLock()
+	defer gv.mu.RUnlock()
+	return gv.GaugeVec
+}
+
+// with 返回限制label组合后的指标，与rebuild互斥
+func (gv *GaugeVec) with(labels []string) prometheus.Gauge {
+	gv.mu.RLock()
+	labels, overflow := gv.limiter.limit(labels)
+	metric := gv.GaugeVec.WithLabelValues(labels...)
+	gv.mu.RUnlock()
+	if overflow {
+		countOverflow(gv.name())
+	}
+	return metric
+}
+
+func (gv *GaugeVec) rebuild(config *Config) {
+	gv.mu.Lock()
+	defer gv.mu.Unlock()
+	opts := gv.opts
+	opts.Namespace = config.Namespace
+	opts.ConstLabels = config.constLabels(opts.ConstLabels, opts.Labels)
+	opts.LabelLimit = config.labelLimit(opts.Name, opts.LabelLimit)
+	gv.GaugeVec = opts.newVec()
+	gv.limiter = newLabelLimiter(opts.Name, opts.LabelLimit, len(opts.Labels))
 }
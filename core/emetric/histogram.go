@@ -1,39 +1,105 @@
 package emetric
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // HistogramVecOpts ...
 type HistogramVecOpts struct {
-	Namespace string
-	Subsystem string
-	Name      string
-	Help      string
-	Labels    []string
-	Buckets   []float64
+	Namespace   string
+	Subsystem   string
+	Name        string
+	Help        string
+	Labels      []string
+	ConstLabels map[string]string
+	LabelLimit  int // label组合数上限，超过后新的组合合并为__other__，小于等于0不限制
+	Buckets     []float64
 }
 
 // HistogramVec ...
 type HistogramVec struct {
 	*prometheus.HistogramVec
+	mu      sync.RWMutex // rebuild时替换vec、limiter
+	opts    HistogramVecOpts
+	limiter *labelLimiter
 }
 
 // Build ...
 func (opts HistogramVecOpts) Build() *HistogramVec {
-	vec := prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Namespace: opts.Namespace,
-			Subsystem: opts.Subsystem,
-			Name:      opts.Name,
-			Help:      opts.Help,
-			Buckets:   opts.Buckets,
-		}, opts.Labels)
-	prometheus.MustRegister(vec)
+	vec := opts.newVec()
+	DefaultRegisterer.MustRegister(vec)
 	return &HistogramVec{
 		HistogramVec: vec,
+		opts:         opts,
+		limiter:      newLabelLimiter(opts.Name, opts.LabelLimit, len(opts.Labels)),
 	}
 }
 
+func (opts HistogramVecOpts) newVec() *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: opts.ConstLabels,
+			Buckets:     opts.Buckets,
+		}, opts.Labels)
+}
+
 // Observe ...
 func (histogram *HistogramVec) Observe(v float64, labels ...string) {
-	histogram.WithLabelValues(labels...).Observe(v)
+	histogram.with(labels).Observe(v)
+}
+
+// ObserveWithTrace 记录观测值，traceID不为空时附带OpenMetrics exemplar，可以从监控跳转到对应的链路
+func (histogram *HistogramVec) ObserveWithTrace(v float64, traceID string, labels ...string) {
+	observer := histogram.with(labels)
+	if traceID == "" {
+		observer.Observe(v)
+		return
+	}
+	if eo, ok := observer.(prometheus.ExemplarObserver); ok {
+		eo.ObserveWithExemplar(v, prometheus.Labels{ExemplarTraceIDKey: traceID})
+		return
+	}
+	observer.Observe(v)
+}
+
+func (histogram *HistogramVec) name() string {
+	return histogram.opts.Name
+}
+
+func (histogram *HistogramVec) collector() prometheus.Collector {
+	histogram.mu.RLock()
+	defer histogram.mu.RUnlock()
+	return histogram.HistogramVec
+}
+
+// with 返回限制label组合后的指标，与rebuild互斥
+func (histogram *HistogramVec) with(labels []string) prometheus.Observer {
+	histogram.mu.RLock()
+	labels, overflow := histogram.limiter.limit(labels)
+	metric := histogram.HistogramVec.WithLabelValues(labels...)
+	histogram.mu.RUnlock()
+	if overflow {
+		countOverflow(histogram.name())
+	}
+	return metric
+}
+
+func (histogram *HistogramVec) rebuild(config *Config) {
+	histogram.mu.Lock()
+	defer histogram.mu.Unlock()
+	opts := histogram.opts
+	opts.Namespace = config.Namespace
+	opts.ConstLabels = config.constLabels(opts.ConstLabels, opts.Labels)
+	if buckets, ok := config.Buckets[opts.Name]; ok {
+		opts.Buckets = buckets
+	}
+	opts.LabelLimit = config.labelLimit(opts.Name, opts.LabelLimit)
+	histogram.HistogramVec = opts.newVec()
+	histogram.limiter = newLabelLimiter(opts.Name, opts.LabelLimit, len(opts.Labels))
 }
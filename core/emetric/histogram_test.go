@@ -0,0 +1,32 @@
+package emetric
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveWithTrace(t *testing.T) {
+	histogram := &HistogramVec{
+		HistogramVec: prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "exemplar_seconds", Buckets: []float64{0.1, 1}}, []string{"method"}),
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(histogram)
+
+	histogram.ObserveWithTrace(0.05, "", "GET./hello")
+	histogram.ObserveWithTrace(0.5, "0af7651916cd43dd", "GET./hello")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	buckets := families[0].GetMetric()[0].GetHistogram().GetBucket()
+	require.Len(t, buckets, 2)
+	assert.Nil(t, buckets[0].GetExemplar())
+	exemplar := buckets[1].GetExemplar()
+	require.NotNil(t, exemplar)
+	assert.Equal(t, 0.5, exemplar.GetValue())
+	assert.Equal(t, ExemplarTraceIDKey, exemplar.GetLabel()[0].GetName())
+	assert.Equal(t, "0af7651916cd43dd", exemplar.GetLabel()[0].GetValue())
+}
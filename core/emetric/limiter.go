@@ -0,0 +1,95 @@
+package emetric
+
+import (
+	"strings"
+	"sync"
+)
+
+// OtherLabelValue 超过label组合数上限后，新的组合中取值过多的label记为该值
+const OtherLabelValue = "__other__"
+
+// DefaultLabelLimit 内置指标默认的label组合数上限
+const DefaultLabelLimit = 2000
+
+// labelLimiter 限制单个指标的label组合数，防止method、peer等来自请求的label导致时间序列数量失控
+type labelLimiter struct {
+	name   string
+	max    int // 小于等于0不限制
+	mu     sync.RWMutex
+	seen   map[string]struct{}
+	values []map[string]struct{} // 每个label在上限内出现过的取值
+}
+
+func newLabelLimiter(name string, max int, labelCount int) *labelLimiter {
+	values := make([]map[string]struct{}, labelCount)
+	for i := range values {
+		values[i] = make(map[string]struct{})
+	}
+	return &labelLimiter{
+		name:   name,
+		max:    max,
+		seen:   make(map[string]struct{}),
+		values: values,
+	}
+}
+
+// limit 返回实际记录的label，已记录过的组合保持不变，超过上限的新组合只把取值过多的label合并为__other__，
+// overflow表示发生了合并，调用方释放指标的锁之后通过countOverflow记录
+func (l *labelLimiter) limit(labels []string) ([]string, bool) {
+	if l == nil || l.max <= 0 || len(labels) != len(l.values) {
+		return labels, false
+	}
+	key := strings.Join(labels, "\xff")
+	l.mu.RLock()
+	_, ok := l.seen[key]
+	l.mu.RUnlock()
+	if ok {
+		return labels, false
+	}
+
+	l.mu.Lock()
+	if _, ok = l.seen[key]; !ok && len(l.seen) < l.max {
+		l.seen[key] = struct{}{}
+		for i, v := range labels {
+			l.values[i][v] = struct{}{}
+		}
+		ok = true
+	}
+	var collapsed []string
+	if !ok {
+		collapsed = l.collapse(labels)
+	}
+	l.mu.Unlock()
+	if ok {
+		return labels, false
+	}
+	return collapsed, true
+}
+
+// countOverflow 记录指标超过label组合数上限的次数，不经过LabelOverflowCounter自身的限制，避免递归
+func countOverflow(name string) {
+	LabelOverflowCounter.incUnlimited(name)
+}
+
+// collapse 把没有出现过的取值合并为__other__，type、code等取值有限的label保持不变。
+// 取值都出现过时只合并取值最多的label，超过上限后时间序列数不超过其余label取值数的乘积
+func (l *labelLimiter) collapse(labels []string) []string {
+	out := make([]string, len(labels))
+	collapsed := false
+	highest := 0
+	for i, v := range labels {
+		if len(l.values[i]) > len(l.values[highest]) {
+			highest = i
+		}
+		if _, ok := l.values[i][v]; ok {
+			out[i] = v
+			continue
+		}
+		out[i] = OtherLabelValue
+		collapsed = true
+	}
+	if !collapsed {
+		out[highest] = OtherLabelValue
+	}
+	return out
+}
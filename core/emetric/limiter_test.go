@@ -0,0 +1,75 @@
+package emetric
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelLimit(t *testing.T) {
+	counter := CounterVecOpts{
+		Namespace:  DefaultNamespace,
+		Name:       "limit_test_total",
+		Labels:     []string{"method", "peer"},
+		LabelLimit: 2,
+	}.Build()
+	defer DefaultRegisterer.Unregister(counter)
+
+	counter.Inc("GET./a", "app1")
+	counter.Inc("GET./b", "app1")
+	counter.Inc("GET./c", "app2")
+	counter.Add(2, "GET./d", "app3")
+	counter.Inc("GET./a", "app1")
+
+	assert.Equal(t, 3, testutil.CollectAndCount(counter))
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter.WithLabelValues("GET./a", "app1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter.WithLabelValues("GET./b", "app1")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(counter.WithLabelValues(OtherLabelValue, OtherLabelValue)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(LabelOverflowCounter.WithLabelValues("limit_test_total")))
+}
+
+func TestLabelLimitCollapseHighCardinality(t *testing.T) {
+	counter := CounterVecOpts{
+		Namespace:  DefaultNamespace,
+		Name:       "limit_collapse_test_total",
+		Labels:     []string{"method", "code"},
+		LabelLimit: 3,
+	}.Build()
+	defer DefaultRegisterer.Unregister(counter)
+
+	counter.Inc("GET./a", "200")
+	counter.Inc("GET./b", "200")
+	counter.Inc("GET./c", "500")
+	// 只合并新出现的method，code保持不变
+	counter.Inc("GET./d", "200")
+	counter.Inc("GET./e", "500")
+	// 取值都出现过的新组合，合并取值最多的method
+	counter.Inc("GET./a", "500")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter.WithLabelValues(OtherLabelValue, "200")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter.WithLabelValues(OtherLabelValue, "500")))
+	assert.Equal(t, 5, testutil.CollectAndCount(counter))
+}
+
+func TestLabelLimitConfig(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	container := DefaultContainer()
+	container.config.LabelLimit = -1
+	container.config.LabelLimits["server_handle_total"] = 1
+	require.NoError(t, container.Build(WithRegisterer(registry)))
+	defer func() {
+		require.NoError(t, DefaultContainer().Build(WithRegisterer(prometheus.DefaultRegisterer)))
+	}()
+
+	ServerHandleCounter.Inc(TypeHTTP, "GET./a", "app1", "200")
+	ServerHandleCounter.Inc(TypeHTTP, "GET./b", "app1", "200")
+	assert.Equal(t, float64(1), testutil.ToFloat64(ServerHandleCounter.WithLabelValues(TypeHTTP, OtherLabelValue, "app1", "200")))
+
+	for _, method := range []string{"GET./a", "GET./b", "GET./c"} {
+		ServerHandleHistogram.Observe(0.1, TypeHTTP, method, "app1")
+	}
+	assert.Equal(t, 3, testutil.CollectAndCount(ServerHandleHistogram))
+}
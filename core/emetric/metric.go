@@ -3,6 +3,8 @@ package emetric
 import (
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/gotomicro/ego/core/eapp"
 )
 
@@ -37,35 +39,57 @@ var (
 
 	// DefaultNamespace ...
 	DefaultNamespace = "ego"
+
+	// ExemplarTraceIDKey exemplar中trace id的label名
+	ExemplarTraceIDKey = "trace_id"
+)
+
+var (
+	// DefaultRegisterer 指标注册的Registerer，默认为prometheus.DefaultRegisterer，可以通过WithRegisterer修改
+	DefaultRegisterer = prometheus.DefaultRegisterer
+	// DefaultGatherer governor的/metrics读取指标的Gatherer，默认为prometheus.DefaultGatherer
+	DefaultGatherer = prometheus.DefaultGatherer
 )
 
 var (
 	// ServerHandleCounter ...
 	ServerHandleCounter = CounterVecOpts{
-		Namespace: DefaultNamespace,
-		Name:      "server_handle_total",
-		Labels:    []string{"type", "method", "peer", "code"},
+		Namespace:  DefaultNamespace,
+		Name:       "server_handle_total",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "method", "peer", "code"},
 	}.Build()
 
 	// ServerHandleHistogram ...
 	ServerHandleHistogram = HistogramVecOpts{
-		Namespace: DefaultNamespace,
-		Name:      "server_handle_seconds",
-		Labels:    []string{"type", "method", "peer"},
+		Namespace:  DefaultNamespace,
+		Name:       "server_handle_seconds",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "method", "peer"},
 	}.Build()
 
 	// ClientHandleCounter ...
 	ClientHandleCounter = CounterVecOpts{
-		Namespace: DefaultNamespace,
-		Name:      "client_handle_total",
-		Labels:    []string{"type", "name", "method", "peer", "code"},
+		Namespace:  DefaultNamespace,
+		Name:       "client_handle_total",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "name", "method", "peer", "code"},
 	}.Build()
 
 	// ClientHandleHistogram ...
 	ClientHandleHistogram = HistogramVecOpts{
-		Namespace: DefaultNamespace,
-		Name:      "client_handle_seconds",
-		Labels:    []string{"type", "name", "method", "peer"},
+		Namespace:  DefaultNamespace,
+		Name:       "client_handle_seconds",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "name", "method", "peer"},
+	}.Build()
+
+	// ClientConnGauge 客户端连接数，state为open(打开的连接)、active(进行中的请求)
+	ClientConnGauge = GaugeVecOpts{
+		Namespace:  DefaultNamespace,
+		Name:       "client_conn_count",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "name", "peer", "state"},
 	}.Build()
 
 	// JobHandleCounter ...
@@ -83,35 +107,61 @@ var (
 	}.Build()
 	// LibHandleHistogram ...
 	LibHandleHistogram = HistogramVecOpts{
-		Namespace: DefaultNamespace,
-		Name:      "lib_handle_seconds",
-		Labels:    []string{"type", "method", "address"},
+		Namespace:  DefaultNamespace,
+		Name:       "lib_handle_seconds",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "method", "address"},
 	}.Build()
 	// LibHandleCounter ...
 	LibHandleCounter = CounterVecOpts{
-		Namespace: DefaultNamespace,
-		Name:      "lib_handle_total",
-		Labels:    []string{"type", "method", "address", "code"},
+		Namespace:  DefaultNamespace,
+		Name:       "lib_handle_total",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "method", "address", "code"},
 	}.Build()
 	// LibHandleSummary ...
 	LibHandleSummary = SummaryVecOpts{
-		Namespace: DefaultNamespace,
-		Name:      "lib_handle_stats",
-		Labels:    []string{"name", "status"},
+		Namespace:  DefaultNamespace,
+		Name:       "lib_handle_stats",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"name", "status"},
 	}.Build()
 
 	// CacheHandleCounter ...
 	CacheHandleCounter = CounterVecOpts{
-		Namespace: DefaultNamespace,
-		Name:      "cache_handle_total",
-		Labels:    []string{"type", "name", "action", "code"},
+		Namespace:  DefaultNamespace,
+		Name:       "cache_handle_total",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "name", "action", "code"},
 	}.Build()
 
 	// CacheHandleHistogram ...
 	CacheHandleHistogram = HistogramVecOpts{
+		Namespace:  DefaultNamespace,
+		Name:       "cache_handle_seconds",
+		LabelLimit: DefaultLabelLimit,
+		Labels:     []string{"type", "name", "action"},
+	}.Build()
+
+	// RegistryStateGauge 实例是否在注册中心中，1为已注册，0为注册失败或者注册丢失
+	RegistryStateGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "registry_registered",
+		Labels:    []string{"name", "scheme", "address"},
+	}.Build()
+
+	// RegistryReregisterCounter 注册失败或者注册丢失后重新注册成功的次数
+	RegistryReregisterCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "registry_reregister_total",
+		Labels:    []string{"name", "scheme", "address"},
+	}.Build()
+
+	// LabelOverflowCounter 超过label组合数上限被合并为__other__的记录次数，metric为指标名(不带前缀)
+	LabelOverflowCounter = CounterVecOpts{
 		Namespace: DefaultNamespace,
-		Name:      "cache_handle_seconds",
-		Labels:    []string{"type", "name", "action"},
+		Name:      "metric_label_overflow_total",
+		Labels:    []string{"metric"},
 	}.Build()
 
 	// BuildInfoGauge ...
@@ -122,7 +172,37 @@ var (
 	}.Build()
 )
 
+// builtin 内置指标，Container.Build时按配置重新构造
+type builtin interface {
+	name() string
+	collector() prometheus.Collector
+	rebuild(config *Config)
+}
+
+var builtins = []builtin{
+	ServerHandleCounter,
+	ServerHandleHistogram,
+	ClientHandleCounter,
+	ClientHandleHistogram,
+	ClientConnGauge,
+	JobHandleCounter,
+	JobHandleHistogram,
+	LibHandleHistogram,
+	LibHandleCounter,
+	LibHandleSummary,
+	CacheHandleCounter,
+	CacheHandleHistogram,
+	RegistryStateGauge,
+	RegistryReregisterCounter,
+	LabelOverflowCounter,
+	BuildInfoGauge,
+}
+
 func init() {
+	setBuildInfo()
+}
+
+func setBuildInfo() {
 	BuildInfoGauge.WithLabelValues(
 		eapp.Name(),
 		eapp.AppMode(),
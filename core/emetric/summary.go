@@ -1,39 +1,88 @@
 package emetric
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // SummaryVecOpts ...
 type SummaryVecOpts struct {
-	Namespace  string
-	Subsystem  string
-	Name       string
-	Help       string
-	Objectives map[float64]float64
-	Labels     []string
+	Namespace   string
+	Subsystem   string
+	Name        string
+	Help        string
+	Objectives  map[float64]float64
+	Labels      []string
+	ConstLabels map[string]string
+	LabelLimit  int // label组合数上限，超过后新的组合合并为__other__，小于等于0不限制
 }
 
 // SummaryVec ...
 type SummaryVec struct {
 	*prometheus.SummaryVec
+	mu      sync.RWMutex // rebuild时替换vec、limiter
+	opts    SummaryVecOpts
+	limiter *labelLimiter
 }
 
 // Build ...
 func (opts SummaryVecOpts) Build() *SummaryVec {
-	vec := prometheus.NewSummaryVec(
-		prometheus.SummaryOpts{
-			Namespace:  opts.Namespace,
-			Subsystem:  opts.Subsystem,
-			Name:       opts.Name,
-			Help:       opts.Help,
-			Objectives: opts.Objectives,
-		}, opts.Labels)
-	prometheus.MustRegister(vec)
+	vec := opts.newVec()
+	DefaultRegisterer.MustRegister(vec)
 	return &SummaryVec{
 		SummaryVec: vec,
+		opts:       opts,
+		limiter:    newLabelLimiter(opts.Name, opts.LabelLimit, len(opts.Labels)),
 	}
 }
 
+func (opts SummaryVecOpts) newVec() *prometheus.SummaryVec {
+	return prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace:   opts.Namespace,
+			Subsystem:   opts.Subsystem,
+			Name:        opts.Name,
+			Help:        opts.Help,
+			ConstLabels: opts.ConstLabels,
+			Objectives:  opts.Objectives,
+		}, opts.Labels)
+}
+
 // Observe ...
 func (summary *SummaryVec) Observe(v float64, labels ...string) {
-	summary.WithLabelValues(labels...).Observe(v)
+	summary.with(labels).Observe(v)
+}
+
+func (summary *SummaryVec) name() string {
+	return summary.opts.Name
+}
+
+func (summary *SummaryVec) collector() prometheus.Collector {
+	summary.mu.RLock()
+	defer summary.mu.RUnlock()
+	return summary.SummaryVec
+}
+
+// with 返回限制label组合后的指标，与rebuild互斥
+func (summary *SummaryVec) with(labels []string) prometheus.Observer {
+	summary.mu.RLock()
+	labels, overflow := summary.limiter.limit(labels)
+	metric := summary.SummaryVec.WithLabelValues(labels...)
+	summary.mu.RUnlock()
+	if overflow {
+		countOverflow(summary.name())
+	}
+	return metric
+}
+
+func (summary *SummaryVec) rebuild(config *Config) {
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	opts := summary.opts
+	opts.Namespace = config.Namespace
+	opts.ConstLabels = config.constLabels(opts.ConstLabels, opts.Labels)
+	opts.LabelLimit = config.labelLimit(opts.Name, opts.LabelLimit)
+	summary.SummaryVec = opts.newVec()
+	summary.limiter = newLabelLimiter(opts.Name, opts.LabelLimit, len(opts.Labels))
 }
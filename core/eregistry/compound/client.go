@@ -2,42 +2,157 @@ package compound
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/errgroup"
 
 	registry2 "github.com/gotomicro/ego/core/eregistry"
 	"github.com/gotomicro/ego/server"
-	"golang.org/x/sync/errgroup"
 )
 
+// PriorityRegistry 带优先级的注册中心，多个注册中心中存在相同地址的实例时，使用优先级高的注册中心中的信息
+type PriorityRegistry struct {
+	registry2.Registry
+	Priority int
+}
+
+var _ registry2.KeepAliver = &compoundRegistry{}
+
 type compoundRegistry struct {
-	registries []registry2.Registry
+	registries []PriorityRegistry
 }
 
-// ListServices ...
-func (c compoundRegistry) ListServices(ctx context.Context, name string, scheme string) ([]*server.ServiceInfo, error) {
+// ListServices 合并所有注册中心的实例，按地址去重
+func (c *compoundRegistry) ListServices(ctx context.Context, name string, scheme string) ([]*server.ServiceInfo, error) {
 	var eg errgroup.Group
-	var services = make([]*server.ServiceInfo, 0)
-	for _, registry := range c.registries {
-		registry := registry
+	// 每个注册中心写入各自的位置，不需要加锁
+	var results = make([][]*server.ServiceInfo, len(c.registries))
+	for i, registry := range c.registries {
+		i, registry := i, registry
 		eg.Go(func() error {
 			infos, err := registry.ListServices(ctx, name, scheme)
 			if err != nil {
 				return err
 			}
-			services = append(services, infos...)
+			results[i] = infos
 			return nil
 		})
 	}
-	err := eg.Wait()
-	return services, err
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var services = make([]*server.ServiceInfo, 0)
+	var seen = make(map[string]struct{})
+	for _, i := range c.byPriority() {
+		for _, info := range results[i] {
+			// 优先级高的注册中心先加入
+			if _, ok := seen[info.Address]; ok {
+				continue
+			}
+			seen[info.Address] = struct{}{}
+			services = append(services, info)
+		}
+	}
+	return services, nil
 }
 
-// WatchServices ...
-func (c compoundRegistry) WatchServices(ctx context.Context, s string, s2 string) (chan registry2.Endpoints, error) {
-	panic("compound registry doesn't support watch services")
+// WatchServices 合并所有注册中心的推送，所有注册中心都推送过一次之后才开始推送。
+// ctx取消或者所有注册中心都关闭了watch时关闭channel
+func (c *compoundRegistry) WatchServices(ctx context.Context, name string, scheme string) (chan registry2.Endpoints, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	sources := make([]chan registry2.Endpoints, len(c.registries))
+	for i, registry := range c.registries {
+		ch, err := registry.WatchServices(ctx, name, scheme)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		sources[i] = ch
+	}
+
+	type update struct {
+		index     int
+		endpoints *registry2.Endpoints // 为nil表示该注册中心关闭了watch
+	}
+	updates := make(chan update)
+	for i, ch := range sources {
+		i, ch := i, ch
+		go func() {
+			for {
+				var u = update{index: i}
+				select {
+				case endpoints, ok := <-ch:
+					if ok {
+						u.endpoints = &endpoints
+					}
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case updates <- u:
+				case <-ctx.Done():
+					return
+				}
+				if u.endpoints == nil {
+					return
+				}
+			}
+		}()
+	}
+
+	out := make(chan registry2.Endpoints, 1)
+	go func() {
+		defer close(out)
+		defer cancel()
+		var (
+			latest   = make([]*registry2.Endpoints, len(sources))
+			received = 0
+			open     = len(sources)
+			last     *registry2.Endpoints
+		)
+		for open > 0 {
+			var u update
+			select {
+			case u = <-updates:
+			case <-ctx.Done():
+				return
+			}
+			if u.endpoints == nil {
+				open--
+				// 没有推送过就关闭的注册中心按空列表处理，保留其他注册中心最后一次推送的信息
+				if latest[u.index] != nil {
+					continue
+				}
+				u.endpoints = registry2.NewEndpoints()
+			}
+			if latest[u.index] == nil {
+				received++
+			}
+			latest[u.index] = u.endpoints
+			if received < len(sources) {
+				continue
+			}
+			merged := c.merge(latest)
+			if last != nil && reflect.DeepEqual(last, merged) {
+				continue
+			}
+			last = merged
+			select {
+			case out <- *merged.DeepCopy():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
 }
 
 // RegisterService ...
-func (c compoundRegistry) RegisterService(ctx context.Context, bean *server.ServiceInfo) error {
+func (c *compoundRegistry) RegisterService(ctx context.Context, bean *server.ServiceInfo) error {
 	var eg errgroup.Group
 	for _, registry := range c.registries {
 		registry := registry
@@ -49,7 +164,7 @@ func (c compoundRegistry) RegisterService(ctx context.Context, bean *server.Serv
 }
 
 // UnregisterService ...
-func (c compoundRegistry) UnregisterService(ctx context.Context, bean *server.ServiceInfo) error {
+func (c *compoundRegistry) UnregisterService(ctx context.Context, bean *server.ServiceInfo) error {
 	var eg errgroup.Group
 	for _, registry := range c.registries {
 		registry := registry
@@ -60,8 +175,51 @@ func (c compoundRegistry) UnregisterService(ctx context.Context, bean *server.Se
 	return eg.Wait()
 }
 
+// KeepAlive 对每个注册中心续约，没有实现KeepAliver的注册中心通过ListServices检查，
+// 任意一个注册中心中的实例丢失时返回eregistry.ErrNotRegistered，由Lease重新注册
+func (c *compoundRegistry) KeepAlive(ctx context.Context, bean *server.ServiceInfo) error {
+	var eg errgroup.Group
+	var errs = make([]error, len(c.registries))
+	for i, registry := range c.registries {
+		i, registry := i, registry
+		eg.Go(func() error {
+			errs[i] = registry2.KeepAlive(ctx, registry.Registry, bean)
+			return nil
+		})
+	}
+	_ = eg.Wait()
+	var firstErr error
+	for _, err := range errs {
+		if errors.Is(err, registry2.ErrNotRegistered) {
+			return registry2.ErrNotRegistered
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// KeepAliveInterval 使用所有注册中心中最短的续约间隔，都没有实现KeepAliver时使用eregistry.DefaultLeaseInterval
+func (c *compoundRegistry) KeepAliveInterval() time.Duration {
+	interval := time.Duration(0)
+	for _, registry := range c.registries {
+		keepAliver, ok := registry.Registry.(registry2.KeepAliver)
+		if !ok {
+			continue
+		}
+		if i := keepAliver.KeepAliveInterval(); interval == 0 || i < interval {
+			interval = i
+		}
+	}
+	if interval == 0 {
+		return registry2.DefaultLeaseInterval
+	}
+	return interval
+}
+
 // Close ...
-func (c compoundRegistry) Close() error {
+func (c *compoundRegistry) Close() error {
 	var eg errgroup.Group
 	for _, registry := range c.registries {
 		registry := registry
@@ -72,9 +230,40 @@ func (c compoundRegistry) Close() error {
 	return eg.Wait()
 }
 
-// New ...
+// merge 按优先级从低到高合并，优先级高的覆盖相同key的信息
+func (c *compoundRegistry) merge(latest []*registry2.Endpoints) *registry2.Endpoints {
+	merged := registry2.NewEndpoints()
+	order := c.byPriority()
+	for i := len(order) - 1; i >= 0; i-- {
+		latest[order[i]].DeepCopyInfo(merged)
+	}
+	return merged
+}
+
+// byPriority 按优先级从高到低返回注册中心的下标，优先级相同时靠前的优先
+func (c *compoundRegistry) byPriority() []int {
+	order := make([]int, len(c.registries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return c.registries[order[i]].Priority > c.registries[order[j]].Priority
+	})
+	return order
+}
+
+// New 组合多个注册中心，靠前的注册中心优先级高
 func New(registries ...registry2.Registry) registry2.Registry {
-	return compoundRegistry{
+	prioritized := make([]PriorityRegistry, 0, len(registries))
+	for i, registry := range registries {
+		prioritized = append(prioritized, PriorityRegistry{Registry: registry, Priority: len(registries) - i})
+	}
+	return NewWithPriority(prioritized...)
+}
+
+// NewWithPriority 组合多个注册中心，并指定各自的优先级
+func NewWithPriority(registries ...PriorityRegistry) registry2.Registry {
+	return &compoundRegistry{
 		registries: registries,
 	}
 }
@@ -0,0 +1,150 @@
+package compound
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/eregistry/ememory"
+	"github.com/gotomicro/ego/core/eregistry/eregistrytest"
+	"github.com/gotomicro/ego/server"
+)
+
+func TestContract(t *testing.T) {
+	eregistrytest.Run(t, func(t *testing.T) eregistry.Registry {
+		return New(ememory.NewStore(), ememory.NewStore())
+	})
+}
+
+func TestMergePriority(t *testing.T) {
+	low, high := ememory.NewStore(), ememory.NewStore()
+	reg := NewWithPriority(PriorityRegistry{Registry: low, Priority: 1}, PriorityRegistry{Registry: high, Priority: 10})
+	defer reg.Close()
+	ctx := context.Background()
+
+	lowInfo := eregistrytest.NewService("user", "grpc", "127.0.0.1:9001")
+	lowInfo.Metadata["source"] = "low"
+	require.NoError(t, low.RegisterService(ctx, lowInfo))
+	require.NoError(t, low.RegisterService(ctx, eregistrytest.NewService("user", "grpc", "127.0.0.1:9002")))
+	highInfo := eregistrytest.NewService("user", "grpc", "127.0.0.1:9001")
+	highInfo.Metadata["source"] = "high"
+	require.NoError(t, high.RegisterService(ctx, highInfo))
+
+	services, err := reg.ListServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	require.Len(t, services, 2)
+	for _, info := range services {
+		if info.Address == "127.0.0.1:9001" {
+			assert.Equal(t, "high", info.Metadata["source"])
+		}
+	}
+
+	ch, err := reg.WatchServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	endpoints := eregistrytest.Receive(t, ch)
+	require.Len(t, endpoints.Nodes, 2)
+	assert.Equal(t, "high", endpoints.Nodes["127.0.0.1:9001"].Metadata["source"])
+
+	// 高优先级的注册中心注销后使用低优先级的信息
+	require.NoError(t, high.UnregisterService(ctx, highInfo))
+	endpoints = eregistrytest.Receive(t, ch)
+	require.Len(t, endpoints.Nodes, 2)
+	assert.Equal(t, "low", endpoints.Nodes["127.0.0.1:9001"].Metadata["source"])
+
+	// 只关闭一个注册中心时保留它最后的信息，全部关闭后关闭channel
+	require.NoError(t, high.Close())
+	require.NoError(t, low.RegisterService(ctx, eregistrytest.NewService("user", "grpc", "127.0.0.1:9003")))
+	assert.Len(t, eregistrytest.Receive(t, ch).Nodes, 3)
+	require.NoError(t, low.Close())
+	eregistrytest.AssertClosed(t, ch)
+}
+
+type brokenRegistry struct {
+	eregistry.Nop
+}
+
+func (brokenRegistry) WatchServices(context.Context, string, string) (chan eregistry.Endpoints, error) {
+	return nil, errors.New("watch fail")
+}
+
+func TestWatchError(t *testing.T) {
+	store := ememory.NewStore()
+	reg := New(store, brokenRegistry{})
+	_, err := reg.WatchServices(context.Background(), "user", "grpc")
+	assert.EqualError(t, err, "watch fail")
+}
+
+// keepAliveRegistry 实现了KeepAliver的注册中心，lost为true时续约返回ErrNotRegistered
+type keepAliveRegistry struct {
+	*ememory.Store
+	interval time.Duration
+	lost     bool
+	calls    int
+}
+
+func (k *keepAliveRegistry) KeepAlive(context.Context, *server.ServiceInfo) error {
+	k.calls++
+	if k.lost {
+		return eregistry.ErrNotRegistered
+	}
+	return nil
+}
+
+func (k *keepAliveRegistry) KeepAliveInterval() time.Duration {
+	return k.interval
+}
+
+func TestKeepAlive(t *testing.T) {
+	store := ememory.NewStore()
+	fast := &keepAliveRegistry{Store: ememory.NewStore(), interval: time.Second}
+	slow := &keepAliveRegistry{Store: ememory.NewStore(), interval: 5 * time.Second}
+	reg := New(store, fast, slow)
+	defer reg.Close()
+	keepAliver, ok := reg.(eregistry.KeepAliver)
+	require.True(t, ok)
+	assert.Equal(t, time.Second, keepAliver.KeepAliveInterval())
+	assert.Equal(t, eregistry.DefaultLeaseInterval, New(store).(eregistry.KeepAliver).KeepAliveInterval())
+
+	ctx := context.Background()
+	info := eregistrytest.NewService("user", "grpc", "127.0.0.1:9001")
+	require.NoError(t, reg.RegisterService(ctx, info))
+	assert.NoError(t, keepAliver.KeepAlive(ctx, info))
+	assert.Equal(t, 1, fast.calls)
+	assert.Equal(t, 1, slow.calls)
+
+	// 任意一个注册中心丢失实例时都需要重新注册
+	slow.lost = true
+	assert.ErrorIs(t, keepAliver.KeepAlive(ctx, info), eregistry.ErrNotRegistered)
+	slow.lost = false
+	require.NoError(t, store.UnregisterService(ctx, info))
+	assert.ErrorIs(t, keepAliver.KeepAlive(ctx, info), eregistry.ErrNotRegistered)
+}
+
+func TestRegisterConsumer(t *testing.T) {
+	first, second := ememory.NewStore(), ememory.NewStore()
+	reg := New(first, second)
+	defer reg.Close()
+
+	eregistry.Register("compound", reg)
+
+	// 同一个组合注册中心复用一个Lease
+	require.NoError(t, eregistry.RegisterConsumer(context.Background(), "compound", "user", "grpc"))
+	require.NoError(t, eregistry.RegisterConsumer(context.Background(), "compound", "order", "grpc"))
+	for _, store := range []*ememory.Store{first, second} {
+		_, endpoints := store.Snapshot("user", "grpc")
+		assert.Len(t, endpoints.ConsumerConfigs, 1)
+		_, endpoints = store.Snapshot("order", "grpc")
+		assert.Len(t, endpoints.ConsumerConfigs, 1)
+	}
+
+	require.NoError(t, eregistry.UnregisterConsumers())
+	for _, store := range []*ememory.Store{first, second} {
+		_, endpoints := store.Snapshot("user", "grpc")
+		assert.Len(t, endpoints.ConsumerConfigs, 0)
+	}
+}
@@ -0,0 +1,165 @@
+package eregistry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
+)
+
+// MetadataConsumerApp 消费方注册信息中调用方应用名的key
+const MetadataConsumerApp = "consumerApp"
+
+// consumerQueryTimeout Topology查询每个服务的消费方的超时时间
+const consumerQueryTimeout = 3 * time.Second
+
+var (
+	// consumerLeases 注册中心名称 => Lease，每个注册中心一个Lease，消费方注册同样需要续约。
+	// 注册中心的动态类型不一定可比较，不能作为map的key
+	consumerLeases   = make(map[string]*Lease)
+	consumerLeasesMu sync.Mutex
+)
+
+// Topology 应用的调用关系
+type Topology struct {
+	App      string                      `json:"app"`
+	Calls    []LeaseState                `json:"calls"`    // 本应用作为消费方注册的服务
+	CalledBy map[string][]ConsumerConfig `json:"calledBy"` // 本应用提供的服务名 => 调用该服务的消费方
+}
+
+// NewConsumerInfo 当前进程作为service消费方的注册信息，同一进程对同一服务只有一条
+func NewConsumerInfo(service string, scheme string) *server.ServiceInfo {
+	info := server.ApplyOptions(
+		server.WithName(service),
+		server.WithScheme(scheme),
+		server.WithAddress(fmt.Sprintf("%s:%d", eapp.HostName(), os.Getpid())),
+		server.WithKind(constant.ServiceConsumer),
+		server.WithMetaData(MetadataConsumerApp, eapp.Name()),
+	)
+	return &info
+}
+
+// NewConsumerConfig 注册中心将消费方的注册信息转换为Endpoints.ConsumerConfigs
+func NewConsumerConfig(info server.ServiceInfo) ConsumerConfig {
+	return ConsumerConfig{
+		ID:     info.Label(),
+		Scheme: info.Scheme,
+		Host:   info.Address,
+		App:    info.Metadata[MetadataConsumerApp],
+	}
+}
+
+// RegisterConsumer 将当前进程注册为service的消费方，name为通过Register注册的注册中心名称，注册后自动续约，失败时按续约间隔重试
+func RegisterConsumer(ctx context.Context, name string, service string, scheme string) error {
+	reg, ok := Get(name)
+	if !ok {
+		return fmt.Errorf("registry %s not registered", name)
+	}
+	consumerLeasesMu.Lock()
+	lease, ok := consumerLeases[name]
+	if !ok {
+		lease = NewLease(reg)
+		consumerLeases[name] = lease
+	}
+	consumerLeasesMu.Unlock()
+	return lease.RegisterService(ctx, NewConsumerInfo(service, scheme))
+}
+
+// UnregisterConsumers 注销所有消费方注册，应用停止时调用
+func UnregisterConsumers() error {
+	consumerLeasesMu.Lock()
+	defer consumerLeasesMu.Unlock()
+	var lastErr error
+	for name, lease := range consumerLeases {
+		for _, info := range lease.infos() {
+			if err := lease.UnregisterService(context.Background(), info); err != nil {
+				lastErr = err
+			}
+		}
+		lease.stop()
+		delete(consumerLeases, name)
+	}
+	return lastErr
+}
+
+// GetTopology 返回本应用调用的服务，以及注册中心中调用本应用服务的消费方
+func GetTopology(ctx context.Context) Topology {
+	topology := Topology{
+		App:      eapp.Name(),
+		Calls:    make([]LeaseState, 0),
+		CalledBy: make(map[string][]ConsumerConfig),
+	}
+	activeLeasesMu.Lock()
+	leases := make([]*Lease, 0, len(activeLeases))
+	for l := range activeLeases {
+		leases = append(leases, l)
+	}
+	activeLeasesMu.Unlock()
+
+	for _, l := range leases {
+		for _, state := range l.States() {
+			if state.Kind == constant.ServiceConsumer.String() {
+				topology.Calls = append(topology.Calls, state)
+				continue
+			}
+			consumers, err := l.consumers(ctx, state.Name, state.Scheme)
+			if err != nil {
+				l.logger.Warn("query consumers", elog.FieldErr(err), elog.FieldName(state.Name))
+				continue
+			}
+			topology.CalledBy[state.Name] = append(topology.CalledBy[state.Name], consumers...)
+		}
+	}
+	sort.Slice(topology.Calls, func(i, j int) bool {
+		return topology.Calls[i].Name < topology.Calls[j].Name
+	})
+	for name, consumers := range topology.CalledBy {
+		topology.CalledBy[name] = dedupConsumers(consumers)
+	}
+	return topology
+}
+
+// consumers 通过watch的第一次推送读取服务当前的消费方
+func (l *Lease) consumers(ctx context.Context, name string, scheme string) ([]ConsumerConfig, error) {
+	ctx, cancel := context.WithTimeout(ctx, consumerQueryTimeout)
+	defer cancel()
+	endpoints, err := l.Registry.WatchServices(ctx, name, scheme)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case endpoint, ok := <-endpoints:
+		if !ok {
+			return nil, context.Canceled
+		}
+		consumers := make([]ConsumerConfig, 0, len(endpoint.ConsumerConfigs))
+		for _, config := range endpoint.ConsumerConfigs {
+			consumers = append(consumers, config)
+		}
+		return consumers, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// dedupConsumers 同一服务在多个scheme下注册时，消费方可能重复
+func dedupConsumers(consumers []ConsumerConfig) []ConsumerConfig {
+	seen := make(map[string]struct{}, len(consumers))
+	out := make([]ConsumerConfig, 0, len(consumers))
+	for _, consumer := range consumers {
+		if _, ok := seen[consumer.ID]; ok {
+			continue
+		}
+		seen[consumer.ID] = struct{}{}
+		out = append(out, consumer)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,353 @@
+package efile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/util/xgo"
+	"github.com/gotomicro/ego/server"
+)
+
+// PackageName 包名
+const PackageName = "registry.efile"
+
+var errReadOnly = errors.New("file registry is read only")
+
+var _ eregistry.Registry = (*Component)(nil)
+
+// Component 基于文件的注册中心，适用于本地开发、集成测试和单机部署。
+// Path为目录时每个服务实例对应一个json文件，多个进程可以共享同一个目录；
+// Path为文件时只读取其中的服务列表，不能注册
+type Component struct {
+	name      string
+	config    *Config
+	logger    *elog.Component
+	dir       string // 注册目录，只读模式下为服务列表文件所在目录
+	file      string // 只读的服务列表文件
+	mu        sync.Mutex
+	watching  bool
+	subs      map[chan struct{}]struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) (*Component, error) {
+	path, err := filepath.Abs(config.Path)
+	if err != nil {
+		return nil, err
+	}
+	comp := &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		dir:    path,
+		subs:   make(map[chan struct{}]struct{}),
+		closed: make(chan struct{}),
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && !info.IsDir():
+		comp.dir, comp.file = filepath.Dir(path), path
+	case os.IsNotExist(err):
+		if err = os.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	}
+	return comp, nil
+}
+
+// Name 配置名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// PackageName 包名
+func (c *Component) PackageName() string {
+	return PackageName
+}
+
+// RegisterService 写入服务实例文件，先写临时文件再重命名，读取方不会读到写了一半的文件
+func (c *Component) RegisterService(ctx context.Context, info *server.ServiceInfo) error {
+	if c.file != "" {
+		return errReadOnly
+	}
+	content, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.servicePath(info))
+}
+
+// UnregisterService 删除服务实例文件
+func (c *Component) UnregisterService(ctx context.Context, info *server.ServiceInfo) error {
+	if c.file != "" {
+		return errReadOnly
+	}
+	if err := os.Remove(c.servicePath(info)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ListServices 列出name下指定scheme的服务提供方
+func (c *Component) ListServices(ctx context.Context, name string, scheme string) ([]*server.ServiceInfo, error) {
+	services, err := c.services()
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]*server.ServiceInfo, 0)
+	for _, info := range services {
+		if isProvider(info, name, scheme) {
+			matched = append(matched, info)
+		}
+	}
+	return matched, nil
+}
+
+// WatchServices 先返回当前的服务列表，之后每次文件变更且服务列表变化时推送一次。
+// ctx取消或者注册中心关闭时关闭channel
+func (c *Component) WatchServices(ctx context.Context, name string, scheme string) (chan eregistry.Endpoints, error) {
+	if err := c.startWatch(); err != nil {
+		return nil, err
+	}
+	changed := make(chan struct{}, 1)
+	c.subscribe(changed)
+	last, err := c.endpoints(ctx, name, scheme)
+	if err != nil {
+		c.unsubscribe(changed)
+		return nil, err
+	}
+
+	out := make(chan eregistry.Endpoints, 1)
+	out <- *last.DeepCopy()
+	xgo.Go(func() {
+		defer close(out)
+		defer c.unsubscribe(changed)
+		for {
+			select {
+			case <-changed:
+				next, err := c.endpoints(ctx, name, scheme)
+				if err != nil {
+					c.logger.Error("read services", elog.FieldErr(err), elog.FieldName(name))
+					continue
+				}
+				if reflect.DeepEqual(last, next) {
+					continue
+				}
+				last = next
+				select {
+				case out <- *next.DeepCopy():
+				case <-ctx.Done():
+					return
+				case <-c.closed:
+					return
+				}
+			case <-ctx.Done():
+				return
+			case <-c.closed:
+				return
+			}
+		}
+	})
+	return out, nil
+}
+
+// Close 停止监听文件，已注册的服务实例文件需要通过UnregisterService删除
+func (c *Component) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return nil
+}
+
+func (c *Component) endpoints(ctx context.Context, name string, scheme string) (*eregistry.Endpoints, error) {
+	services, err := c.services()
+	if err != nil {
+		return nil, err
+	}
+	endpoints := eregistry.NewEndpoints()
+	for _, info := range services {
+		switch {
+		case isProvider(info, name, scheme):
+			endpoints.Nodes[info.Address] = *info
+		case isConsumer(info, name, scheme):
+			config := eregistry.NewConsumerConfig(*info)
+			endpoints.ConsumerConfigs[config.ID] = config
+		}
+	}
+	return endpoints, nil
+}
+
+// services 读取全部服务实例，无法解析的文件跳过，不影响其他实例
+func (c *Component) services() ([]*server.ServiceInfo, error) {
+	services := make([]*server.ServiceInfo, 0)
+	if c.file != "" {
+		content, err := ioutil.ReadFile(c.file)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(content, &services); err != nil {
+			return nil, fmt.Errorf("unmarshal %s fail, %w", c.file, err)
+		}
+		return services, nil
+	}
+
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !isServiceFile(entry.Name()) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(c.dir, entry.Name()))
+		if err != nil {
+			// 读取过程中被删除
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		var info server.ServiceInfo
+		if err = json.Unmarshal(content, &info); err != nil {
+			c.logger.Warn("skip invalid service file", elog.FieldErr(err), elog.FieldName(entry.Name()))
+			continue
+		}
+		services = append(services, &info)
+	}
+	return services, nil
+}
+
+// servicePath 服务实例文件路径，文件名由服务名、类型和地址转义得到
+func (c *Component) servicePath(info *server.ServiceInfo) string {
+	key := fmt.Sprintf("%s_%s_%s", info.Name, info.Kind.String(), info.Label())
+	return filepath.Join(c.dir, url.QueryEscape(key)+".json")
+}
+
+// startWatch 第一次Watch时开始监听目录
+func (c *Component) startWatch() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watching {
+		return nil
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err = w.Add(c.dir); err != nil {
+		_ = w.Close()
+		return err
+	}
+	c.watching = true
+	xgo.Go(func() {
+		c.watch(w)
+	})
+	return nil
+}
+
+// watch 合并Debounce时间内的多次变更，只通知一次
+func (c *Component) watch(w *fsnotify.Watcher) {
+	defer w.Close()
+	var fire <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if !c.interested(event.Name) {
+				continue
+			}
+			if fire == nil {
+				fire = time.After(c.config.Debounce)
+			}
+		case <-fire:
+			fire = nil
+			c.notify()
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			c.logger.Error("watch registry dir", elog.FieldErr(err), elog.FieldAddr(c.dir))
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *Component) interested(name string) bool {
+	if c.file != "" {
+		return filepath.Clean(name) == c.file
+	}
+	return isServiceFile(filepath.Base(name))
+}
+
+func (c *Component) notify() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ch := range c.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *Component) subscribe(ch chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subs[ch] = struct{}{}
+}
+
+func (c *Component) unsubscribe(ch chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.subs, ch)
+}
+
+func isServiceFile(name string) bool {
+	return !strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".json")
+}
+
+// isConsumer ...
+func isConsumer(info *server.ServiceInfo, name string, scheme string) bool {
+	return info.Name == name && info.Scheme == scheme && info.Kind == constant.ServiceConsumer
+}
+
+// isProvider 只读模式下手写的服务列表可以不填kind
+func isProvider(info *server.ServiceInfo, name string, scheme string) bool {
+	if info.Name != name || info.Scheme != scheme {
+		return false
+	}
+	return info.Kind == constant.ServiceProvider || info.Kind == constant.ServiceUnknown
+}
@@ -0,0 +1,87 @@
+package efile
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/eregistry/eregistrytest"
+)
+
+func TestDirRegistry(t *testing.T) {
+	reg := DefaultContainer().Build(WithPath(t.TempDir()), WithDebounce(10*time.Millisecond))
+	defer reg.Close()
+	ctx := context.Background()
+
+	require.NoError(t, reg.RegisterService(ctx, eregistrytest.NewService("user", "grpc", "127.0.0.1:9001")))
+	require.NoError(t, reg.RegisterService(ctx, eregistrytest.NewService("user", "http", "127.0.0.1:9002")))
+	require.NoError(t, reg.RegisterService(ctx, eregistrytest.NewService("order", "grpc", "127.0.0.1:9003")))
+	// 无法解析的文件、临时文件不影响读取
+	require.NoError(t, ioutil.WriteFile(filepath.Join(reg.dir, "broken.json"), []byte("{"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(reg.dir, ".tmp-1"), []byte("{"), 0644))
+
+	services, err := reg.ListServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "127.0.0.1:9001", services[0].Address)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	ch, err := reg.WatchServices(watchCtx, "user", "grpc")
+	require.NoError(t, err)
+	endpoints := eregistrytest.Receive(t, ch)
+	assert.Len(t, endpoints.Nodes, 1)
+
+	second := eregistrytest.NewService("user", "grpc", "127.0.0.1:9004")
+	require.NoError(t, reg.RegisterService(ctx, second))
+	endpoints = eregistrytest.Receive(t, ch)
+	assert.Len(t, endpoints.Nodes, 2)
+	assert.Equal(t, "user", endpoints.Nodes["127.0.0.1:9004"].Name)
+
+	require.NoError(t, reg.UnregisterService(ctx, second))
+	endpoints = eregistrytest.Receive(t, ch)
+	assert.Len(t, endpoints.Nodes, 1)
+	assert.Contains(t, endpoints.Nodes, "127.0.0.1:9001")
+	// 重复注销不报错
+	require.NoError(t, reg.UnregisterService(ctx, second))
+
+	cancel()
+	eregistrytest.AssertClosed(t, ch)
+}
+
+func TestFileRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[{"name":"user","scheme":"grpc","address":"127.0.0.1:9001"}]`), 0644))
+	reg := DefaultContainer().Build(WithPath(path), WithDebounce(10*time.Millisecond))
+	ctx := context.Background()
+
+	assert.Equal(t, errReadOnly, reg.RegisterService(ctx, eregistrytest.NewService("user", "grpc", "127.0.0.1:9002")))
+	services, err := reg.ListServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+
+	ch, err := reg.WatchServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	assert.Len(t, eregistrytest.Receive(t, ch).Nodes, 1)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[
+		{"name":"user","scheme":"grpc","address":"127.0.0.1:9001"},
+		{"name":"user","scheme":"grpc","address":"127.0.0.1:9002"}
+	]`), 0644))
+	assert.Len(t, eregistrytest.Receive(t, ch).Nodes, 2)
+
+	// 关闭注册中心后关闭channel
+	require.NoError(t, reg.Close())
+	eregistrytest.AssertClosed(t, ch)
+}
+
+func TestContract(t *testing.T) {
+	eregistrytest.Run(t, func(t *testing.T) eregistry.Registry {
+		return DefaultContainer().Build(WithPath(t.TempDir()), WithDebounce(10*time.Millisecond))
+	})
+}
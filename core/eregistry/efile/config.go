@@ -0,0 +1,20 @@
+package efile
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 文件注册中心配置
+type Config struct {
+	Path     string        // 注册目录，每个服务实例一个json文件；也可以是只读的服务列表文件(ServiceInfo的json数组)
+	Debounce time.Duration // 文件变更后合并通知的等待时间，默认100ms
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Debounce: xtime.Duration("100ms"),
+	}
+}
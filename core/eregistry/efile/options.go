@@ -0,0 +1,20 @@
+package efile
+
+import "time"
+
+// Option 选项
+type Option func(c *Container)
+
+// WithPath 设置注册目录或者服务列表文件
+func WithPath(path string) Option {
+	return func(c *Container) {
+		c.config.Path = path
+	}
+}
+
+// WithDebounce 设置文件变更后合并通知的等待时间
+func WithDebounce(debounce time.Duration) Option {
+	return func(c *Container) {
+		c.config.Debounce = debounce
+	}
+}
@@ -0,0 +1,205 @@
+package ememory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/util/xgo"
+	"github.com/gotomicro/ego/server"
+)
+
+// PackageName 包名
+const PackageName = "registry.ememory"
+
+// retryInterval 长轮询失败后重试的间隔
+const retryInterval = time.Second
+
+var (
+	_ eregistry.Registry   = (*Component)(nil)
+	_ eregistry.KeepAliver = (*Component)(nil)
+)
+
+// Component 内存注册中心的HTTP客户端，注册的实例在TTL后过期，需要通过eregistry.Lease续期，通过长轮询watch
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	client *http.Client
+	ctx    context.Context // Close时取消
+	cancel context.CancelFunc
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) *Component {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		client: &http.Client{},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Name 配置名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// PackageName 包名
+func (c *Component) PackageName() string {
+	return PackageName
+}
+
+// RegisterService 注册实例，实例在TTL后过期。Component不会自动续期，
+// 直接调用时需要自行定期调用KeepAlive，或者使用eregistry.NewLease包装后注册(Ego.Registry设置的注册中心已自动包装)
+func (c *Component) RegisterService(ctx context.Context, info *server.ServiceInfo) error {
+	query := url.Values{"ttl": {c.config.TTL.String()}}
+	return c.do(ctx, c.config.Timeout, http.MethodPut, pathServices, query, info, nil)
+}
+
+// KeepAlive 续期，实例已过期或者注册中心重启后返回eregistry.ErrNotRegistered
+func (c *Component) KeepAlive(ctx context.Context, info *server.ServiceInfo) error {
+	query := url.Values{"ttl": {c.config.TTL.String()}}
+	err := c.do(ctx, c.config.Timeout, http.MethodPost, pathKeepAlive, query, info, nil)
+	if se, ok := err.(*statusError); ok && se.code == http.StatusNotFound {
+		return eregistry.ErrNotRegistered
+	}
+	return err
+}
+
+// KeepAliveInterval 每TTL/3续期一次
+func (c *Component) KeepAliveInterval() time.Duration {
+	return c.config.TTL / 3
+}
+
+// UnregisterService 注销实例
+func (c *Component) UnregisterService(ctx context.Context, info *server.ServiceInfo) error {
+	return c.do(ctx, c.config.Timeout, http.MethodDelete, pathServices, nil, info, nil)
+}
+
+// ListServices ...
+func (c *Component) ListServices(ctx context.Context, name string, scheme string) ([]*server.ServiceInfo, error) {
+	services := make([]*server.ServiceInfo, 0)
+	query := url.Values{"name": {name}, "scheme": {scheme}}
+	if err := c.do(ctx, c.config.Timeout, http.MethodGet, pathServices, query, nil, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// WatchServices 先同步获取当前的服务列表，之后通过长轮询在列表变化时推送，请求失败时按retryInterval重试。
+// ctx取消或者Close时关闭channel
+func (c *Component) WatchServices(ctx context.Context, name string, scheme string) (chan eregistry.Endpoints, error) {
+	query := url.Values{"name": {name}, "scheme": {scheme}}
+	var last watchResponse
+	if err := c.do(ctx, c.config.Timeout, http.MethodGet, pathWatch, query, nil, &last); err != nil {
+		return nil, err
+	}
+
+	// Close时同样结束watch
+	ctx, cancel := context.WithCancel(ctx)
+	xgo.Go(func() {
+		select {
+		case <-c.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	})
+
+	out := make(chan eregistry.Endpoints, 1)
+	out <- *last.Endpoints.DeepCopy()
+	xgo.Go(func() {
+		defer close(out)
+		defer cancel()
+		for ctx.Err() == nil {
+			query.Set("revision", strconv.FormatUint(last.Revision, 10))
+			query.Set("timeout", c.config.WatchTimeout.String())
+			var next watchResponse
+			if err := c.do(ctx, c.config.WatchTimeout+c.config.Timeout, http.MethodGet, pathWatch, query, nil, &next); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.logger.Error("watch services", elog.FieldErr(err), elog.FieldName(name), elog.FieldAddr(c.config.Addr))
+				select {
+				case <-time.After(retryInterval):
+				case <-ctx.Done():
+				}
+				continue
+			}
+			changed := !reflect.DeepEqual(last.Endpoints, next.Endpoints)
+			last = next
+			if !changed {
+				continue
+			}
+			select {
+			case out <- *next.Endpoints.DeepCopy():
+			case <-ctx.Done():
+			}
+		}
+	})
+	return out, nil
+}
+
+// Close 停止watch，已注册的实例需要通过UnregisterService注销，否则在TTL之后过期
+func (c *Component) Close() error {
+	c.cancel()
+	return nil
+}
+
+func (c *Component) do(ctx context.Context, timeout time.Duration, method, path string, query url.Values, body interface{}, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	target := strings.TrimSuffix(c.config.Addr, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return &statusError{code: resp.StatusCode, msg: fmt.Sprintf("%s %s fail, status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(content)))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(content, out)
+}
+
+// statusError 注册中心返回的非2xx响应
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
@@ -0,0 +1,177 @@
+package ememory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/eregistry/eregistrytest"
+	"github.com/gotomicro/ego/server"
+)
+
+func newTestServer(t *testing.T) (*Store, *httptest.Server) {
+	store := NewStore()
+	srv := httptest.NewServer(NewHandler(store))
+	t.Cleanup(func() {
+		_ = store.Close()
+		srv.Close()
+	})
+	return store, srv
+}
+
+func TestStoreContract(t *testing.T) {
+	eregistrytest.Run(t, func(t *testing.T) eregistry.Registry {
+		return NewStore()
+	})
+}
+
+func TestClientContract(t *testing.T) {
+	eregistrytest.Run(t, func(t *testing.T) eregistry.Registry {
+		_, srv := newTestServer(t)
+		return DefaultContainer().Build(WithAddr(srv.URL), WithWatchTimeout(time.Second))
+	})
+}
+
+func TestClientTTL(t *testing.T) {
+	store, srv := newTestServer(t)
+	ctx := context.Background()
+	alive := eregistry.NewLease(DefaultContainer().Build(WithAddr(srv.URL), WithTTL(150*time.Millisecond)))
+	crashed := DefaultContainer().Build(WithAddr(srv.URL), WithTTL(150*time.Millisecond))
+	require.NoError(t, alive.RegisterService(ctx, eregistrytest.NewService("user", "grpc", "127.0.0.1:9001")))
+	require.NoError(t, crashed.RegisterService(ctx, eregistrytest.NewService("user", "grpc", "127.0.0.1:9002")))
+
+	ch, err := store.WatchServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	assert.Len(t, eregistrytest.Receive(t, ch).Nodes, 2)
+
+	// 没有续期的实例在TTL之后过期，持续续期的实例不受影响
+	endpoints := eregistrytest.Receive(t, ch)
+	require.Len(t, endpoints.Nodes, 1)
+	assert.Contains(t, endpoints.Nodes, "127.0.0.1:9001")
+
+	time.Sleep(300 * time.Millisecond)
+	assert.Len(t, store.List("user", "grpc"), 1)
+	require.NoError(t, alive.Close())
+}
+
+func TestLeaseReregister(t *testing.T) {
+	store, srv := newTestServer(t)
+	ctx := context.Background()
+	info := eregistrytest.NewService("user", "grpc", "127.0.0.1:9001")
+	reg := eregistry.NewLease(DefaultContainer().Build(WithAddr(srv.URL), WithTTL(time.Minute)), eregistry.WithLeaseInterval(20*time.Millisecond))
+	defer reg.Close()
+	require.NoError(t, reg.RegisterService(ctx, info))
+
+	// 模拟注册中心重启丢失了实例，续期返回404后重新注册
+	store.Delete(info)
+	assert.Eventually(t, func() bool {
+		return len(store.List("user", "grpc")) == 1
+	}, time.Second, 10*time.Millisecond)
+	states := reg.States()
+	require.Len(t, states, 1)
+	assert.True(t, states[0].Registered)
+
+	// 注销后不再续期
+	require.NoError(t, reg.UnregisterService(ctx, info))
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, store.List("user", "grpc"), 0)
+	assert.Len(t, reg.States(), 0)
+}
+
+func TestProviderConfig(t *testing.T) {
+	store, srv := newTestServer(t)
+	ctx := context.Background()
+	info := eregistrytest.NewService("user", "grpc", "127.0.0.1:9001")
+	reg := eregistry.NewLease(DefaultContainer().Build(WithAddr(srv.URL), WithTTL(time.Minute)))
+	defer reg.Close()
+	require.NoError(t, reg.RegisterService(ctx, info))
+
+	// 通过HTTP接口关闭实例并修改metadata
+	config := eregistry.ProviderConfig{ID: "offline", Host: "127.0.0.1:9001", Metadata: map[string]string{"version": "v2"}, EnableSet: true}
+	body, _ := json.Marshal(config)
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/providers/configs?name=user", bytes.NewReader(body))
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Eventually(t, func() bool {
+		services := store.List("user", "grpc")
+		return len(services) == 1 && !services[0].Enable && services[0].Metadata["version"] == "v2"
+	}, time.Second, 10*time.Millisecond)
+	current := server.ApplyOverride(*info)
+	assert.False(t, current.Enable)
+	assert.Equal(t, "v2", current.Metadata["version"])
+	assert.Empty(t, info.Metadata["version"])
+
+	// 其他实例的配置不生效，删除配置后恢复
+	store.PutProviderConfig("user", eregistry.ProviderConfig{ID: "other", Host: "127.0.0.1:9002", Deployment: "gray"})
+	store.DeleteProviderConfig("user", "offline")
+	assert.Eventually(t, func() bool {
+		services := store.List("user", "grpc")
+		return len(services) == 1 && services[0].Enable && services[0].Deployment == ""
+	}, time.Second, 10*time.Millisecond)
+	assert.True(t, server.ApplyOverride(*info).Enable)
+
+	require.NoError(t, reg.UnregisterService(ctx, info))
+}
+
+func TestProviderConfigMetadataOnly(t *testing.T) {
+	store, srv := newTestServer(t)
+	ctx := context.Background()
+	info := eregistrytest.NewService("user", "grpc", "127.0.0.1:9001")
+	reg := eregistry.NewLease(DefaultContainer().Build(WithAddr(srv.URL), WithTTL(time.Minute)))
+	defer reg.Close()
+	require.NoError(t, reg.RegisterService(ctx, info))
+
+	// 只修改metadata时不改变Enable
+	store.PutProviderConfig("user", eregistry.ProviderConfig{ID: "version", Host: "127.0.0.1:9001", Metadata: map[string]string{"version": "v2"}})
+	assert.Eventually(t, func() bool {
+		services := store.List("user", "grpc")
+		return len(services) == 1 && services[0].Metadata["version"] == "v2"
+	}, time.Second, 10*time.Millisecond)
+	assert.True(t, store.List("user", "grpc")[0].Enable)
+	assert.True(t, server.ApplyOverride(*info).Enable)
+
+	require.NoError(t, reg.UnregisterService(ctx, info))
+}
+
+func TestWatchLongPoll(t *testing.T) {
+	store, srv := newTestServer(t)
+	ctx := context.Background()
+	client := DefaultContainer().Build(WithAddr(srv.URL), WithWatchTimeout(50*time.Millisecond))
+	defer client.Close()
+
+	ch, err := client.WatchServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	assert.Len(t, eregistrytest.Receive(t, ch).Nodes, 0)
+
+	// 长轮询超时多次后仍能收到变化
+	time.Sleep(200 * time.Millisecond)
+	store.Put(eregistrytest.NewService("user", "grpc", "127.0.0.1:9001"), 0)
+	assert.Len(t, eregistrytest.Receive(t, ch).Nodes, 1)
+}
+
+func TestWatchRetry(t *testing.T) {
+	store, srv := newTestServer(t)
+	client := DefaultContainer().Build(WithAddr(srv.URL), WithWatchTimeout(50*time.Millisecond))
+	defer client.Close()
+	ch, err := client.WatchServices(context.Background(), "user", "grpc")
+	require.NoError(t, err)
+	eregistrytest.Receive(t, ch)
+
+	// 注册中心不可用时重试，恢复后继续推送
+	srv.Config.SetKeepAlivesEnabled(false)
+	srv.CloseClientConnections()
+	store.Put(eregistrytest.NewService("user", "grpc", "127.0.0.1:9001"), 0)
+	assert.Len(t, eregistrytest.Receive(t, ch).Nodes, 1)
+
+	_, err = DefaultContainer().Build(WithAddr("http://127.0.0.1:1")).WatchServices(context.Background(), "user", "grpc")
+	assert.Error(t, err)
+}
@@ -0,0 +1,24 @@
+package ememory
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 内存注册中心客户端配置
+type Config struct {
+	Addr         string        // 注册中心地址，例如 http://127.0.0.1:9003/registry
+	TTL          time.Duration // 注册的有效期，默认10s，eregistry.Lease每TTL/3续期一次
+	Timeout      time.Duration // 请求超时时间，默认3s
+	WatchTimeout time.Duration // 长轮询的等待时间，默认30s
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		TTL:          xtime.Duration("10s"),
+		Timeout:      xtime.Duration("3s"),
+		WatchTimeout: xtime.Duration("30s"),
+	}
+}
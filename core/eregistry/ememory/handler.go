@@ -0,0 +1,150 @@
+package ememory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+const (
+	pathServices  = "/services"
+	pathKeepAlive = "/services/keepalive"
+	pathWatch     = "/watch"
+	pathProviders = "/providers/configs"
+	// maxWatchTimeout 长轮询最长的等待时间
+	maxWatchTimeout = time.Minute
+)
+
+// watchResponse 长轮询的返回
+type watchResponse struct {
+	Revision  uint64              `json:"revision"`
+	Endpoints eregistry.Endpoints `json:"endpoints"`
+}
+
+// NewHandler 将Store以HTTP接口提供给其他进程，可以挂在governor上，例如
+//
+//	egovernor.HandleFunc("/registry/", http.StripPrefix("/registry", ememory.NewHandler(store)).ServeHTTP)
+//
+// 接口：
+//
+//	PUT    /services?ttl=10s          注册，body为ServiceInfo
+//	POST   /services/keepalive?ttl=10s  续期，body为ServiceInfo，实例不存在时返回404
+//	DELETE /services                  注销，body为ServiceInfo
+//	GET    /services?name=&scheme=    列出服务
+//	GET    /watch?name=&scheme=&revision=&timeout=30s  长轮询，版本号大于revision或者超时后返回，不带revision时立即返回
+//	PUT    /providers/configs?name=   设置服务提供方配置，body为ProviderConfig
+//	DELETE /providers/configs?name=&id=  删除服务提供方配置
+func NewHandler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathServices, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut, http.MethodDelete:
+			var info server.ServiceInfo
+			if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				store.Delete(&info)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			ttl, err := parseDuration(r.URL.Query().Get("ttl"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			store.Put(&info, ttl)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodGet:
+			query := r.URL.Query()
+			writeJSON(w, store.List(query.Get("name"), query.Get("scheme")))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc(pathProviders, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.Method {
+		case http.MethodPut:
+			var config eregistry.ProviderConfig
+			if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			store.PutProviderConfig(query.Get("name"), config)
+		case http.MethodDelete:
+			store.DeleteProviderConfig(query.Get("name"), query.Get("id"))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc(pathKeepAlive, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var info server.ServiceInfo
+		if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ttl, err := parseDuration(r.URL.Query().Get("ttl"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err = store.KeepAlive(&info, ttl); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc(pathWatch, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		query := r.URL.Query()
+		timeout, err := parseDuration(query.Get("timeout"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if timeout <= 0 || timeout > maxWatchTimeout {
+			timeout = maxWatchTimeout
+		}
+		if value := query.Get("revision"); value != "" {
+			revision, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			store.Wait(ctx, revision)
+			cancel()
+		}
+		revision, endpoints := store.Snapshot(query.Get("name"), query.Get("scheme"))
+		writeJSON(w, watchResponse{Revision: revision, Endpoints: *endpoints})
+	})
+	return mux
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,27 @@
+package ememory
+
+import "time"
+
+// Option 选项
+type Option func(c *Container)
+
+// WithAddr 设置注册中心地址
+func WithAddr(addr string) Option {
+	return func(c *Container) {
+		c.config.Addr = addr
+	}
+}
+
+// WithTTL 设置注册的有效期
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Container) {
+		c.config.TTL = ttl
+	}
+}
+
+// WithWatchTimeout 设置长轮询的等待时间
+func WithWatchTimeout(timeout time.Duration) Option {
+	return func(c *Container) {
+		c.config.WatchTimeout = timeout
+	}
+}
@@ -0,0 +1,260 @@
+package ememory
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/util/xgo"
+	"github.com/gotomicro/ego/server"
+)
+
+var _ eregistry.Registry = (*Store)(nil)
+
+// Store 内存注册中心，实现了eregistry.Registry，可以在进程内直接使用，也可以通过NewHandler提供给其他进程
+type Store struct {
+	mu        sync.Mutex
+	revision  uint64                                         // 每次服务列表变化时加1
+	entries   map[string]*entry                              // key为eregistry.GetServiceKey
+	providers map[string]map[string]eregistry.ProviderConfig // 服务名 => 配置ID => 服务提供方配置
+	changed   chan struct{}                                  // 服务列表变化时关闭并替换，用于唤醒watch
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type entry struct {
+	info     server.ServiceInfo
+	expireAt time.Time // 为零值时不过期
+}
+
+// NewStore 构造内存注册中心
+func NewStore() *Store {
+	return &Store{
+		entries:   make(map[string]*entry),
+		providers: make(map[string]map[string]eregistry.ProviderConfig),
+		changed:   make(chan struct{}),
+		closed:    make(chan struct{}),
+	}
+}
+
+// Put 注册服务实例，ttl大于0时需要在ttl内重新Put，否则实例过期被删除。
+// 信息不变的重复Put只续期，不唤醒watch
+func (s *Store) Put(info *server.ServiceInfo, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := eregistry.GetServiceKey(PackageName, info)
+	e := &entry{info: cloneInfo(info)}
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+		time.AfterFunc(ttl, s.expire)
+	}
+	old, ok := s.entries[key]
+	s.entries[key] = e
+	if !ok || !reflect.DeepEqual(old.info, e.info) {
+		s.notifyLocked()
+	}
+}
+
+// KeepAlive 续期，实例不存在时返回eregistry.ErrNotRegistered
+func (s *Store) KeepAlive(info *server.ServiceInfo, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[eregistry.GetServiceKey(PackageName, info)]
+	if !ok {
+		return eregistry.ErrNotRegistered
+	}
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+		time.AfterFunc(ttl, s.expire)
+	}
+	return nil
+}
+
+// Delete 注销服务实例
+func (s *Store) Delete(info *server.ServiceInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := eregistry.GetServiceKey(PackageName, info)
+	if _, ok := s.entries[key]; ok {
+		delete(s.entries, key)
+		s.notifyLocked()
+	}
+}
+
+// PutProviderConfig 设置服务提供方的配置，服务提供方watch到后在运行时生效，例如关闭某个实例
+func (s *Store) PutProviderConfig(name string, config eregistry.ProviderConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	configs, ok := s.providers[name]
+	if !ok {
+		configs = make(map[string]eregistry.ProviderConfig)
+		s.providers[name] = configs
+	}
+	if old, ok := configs[config.ID]; ok && reflect.DeepEqual(old, config) {
+		return
+	}
+	configs[config.ID] = config
+	s.notifyLocked()
+}
+
+// DeleteProviderConfig 删除服务提供方的配置
+func (s *Store) DeleteProviderConfig(name string, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.providers[name][id]; ok {
+		delete(s.providers[name], id)
+		s.notifyLocked()
+	}
+}
+
+// List 按地址排序返回name下指定scheme的服务提供方
+func (s *Store) List(name string, scheme string) []*server.ServiceInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.listLocked(name, scheme)
+}
+
+// Snapshot 返回当前版本号和name下指定scheme的服务列表
+func (s *Store) Snapshot(name string, scheme string) (uint64, *eregistry.Endpoints) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	endpoints := eregistry.NewEndpoints()
+	for _, info := range s.listLocked(name, scheme) {
+		endpoints.Nodes[info.Address] = *info
+	}
+	for _, e := range s.entries {
+		if e.info.Name == name && e.info.Scheme == scheme && e.info.Kind == constant.ServiceConsumer {
+			config := eregistry.NewConsumerConfig(e.info)
+			endpoints.ConsumerConfigs[config.ID] = config
+		}
+	}
+	for id, config := range s.providers[name] {
+		if config.Scheme == "" || config.Scheme == scheme {
+			endpoints.ProviderConfigs[id] = config
+		}
+	}
+	return s.revision, endpoints
+}
+
+// Wait 阻塞直到版本号大于revision，返回false表示ctx取消或者注册中心已关闭
+func (s *Store) Wait(ctx context.Context, revision uint64) bool {
+	for {
+		s.mu.Lock()
+		current, changed := s.revision, s.changed
+		s.mu.Unlock()
+		if current > revision {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false
+		case <-s.closed:
+			return false
+		}
+	}
+}
+
+// RegisterService 注册的实例不过期
+func (s *Store) RegisterService(ctx context.Context, info *server.ServiceInfo) error {
+	s.Put(info, 0)
+	return nil
+}
+
+// UnregisterService ...
+func (s *Store) UnregisterService(ctx context.Context, info *server.ServiceInfo) error {
+	s.Delete(info)
+	return nil
+}
+
+// ListServices ...
+func (s *Store) ListServices(ctx context.Context, name string, scheme string) ([]*server.ServiceInfo, error) {
+	return s.List(name, scheme), nil
+}
+
+// WatchServices 先返回当前的服务列表，之后在列表变化时推送
+func (s *Store) WatchServices(ctx context.Context, name string, scheme string) (chan eregistry.Endpoints, error) {
+	revision, last := s.Snapshot(name, scheme)
+	out := make(chan eregistry.Endpoints, 1)
+	out <- *last.DeepCopy()
+	xgo.Go(func() {
+		defer close(out)
+		for s.Wait(ctx, revision) {
+			var next *eregistry.Endpoints
+			revision, next = s.Snapshot(name, scheme)
+			if reflect.DeepEqual(last, next) {
+				continue
+			}
+			last = next
+			select {
+			case out <- *next.DeepCopy():
+			case <-ctx.Done():
+				return
+			case <-s.closed:
+				return
+			}
+		}
+	})
+	return out, nil
+}
+
+// Close 唤醒并结束所有watch
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	return nil
+}
+
+// expire 删除过期的实例
+func (s *Store) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	expired := false
+	for key, e := range s.entries {
+		if !e.expireAt.IsZero() && !now.Before(e.expireAt) {
+			delete(s.entries, key)
+			expired = true
+		}
+	}
+	if expired {
+		s.notifyLocked()
+	}
+}
+
+func (s *Store) notifyLocked() {
+	s.revision++
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Store) listLocked(name string, scheme string) []*server.ServiceInfo {
+	services := make([]*server.ServiceInfo, 0)
+	for _, e := range s.entries {
+		info := e.info
+		if info.Name != name || info.Scheme != scheme {
+			continue
+		}
+		if info.Kind == constant.ServiceProvider || info.Kind == constant.ServiceUnknown {
+			services = append(services, &info)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Address < services[j].Address
+	})
+	return services
+}
+
+// cloneInfo 深拷贝服务信息，调用方之后修改Metadata等字段不影响已注册的信息
+func cloneInfo(info *server.ServiceInfo) server.ServiceInfo {
+	var out server.ServiceInfo
+	content, _ := json.Marshal(info)
+	_ = json.Unmarshal(content, &out)
+	return out
+}
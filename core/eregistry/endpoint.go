@@ -21,7 +21,8 @@ type Endpoints struct {
 	ProviderConfigs map[string]ProviderConfig `json:"providerConfigs"`
 }
 
-func newEndpoints() *Endpoints {
+// NewEndpoints 构造空的Endpoints
+func NewEndpoints() *Endpoints {
 	return &Endpoints{
 		Nodes:           make(map[string]server.ServiceInfo),
 		RouteConfigs:    make(map[string]RouteConfig),
@@ -36,7 +37,7 @@ func (in *Endpoints) DeepCopy() *Endpoints {
 		return nil
 	}
 
-	out := newEndpoints()
+	out := NewEndpoints()
 	in.DeepCopyInfo(out)
 	return out
 }
@@ -68,6 +69,35 @@ type ProviderConfig struct {
 	Deployment string            `json:"deployment"`
 	Metadata   map[string]string `json:"metadata"`
 	Enable     bool              `json:"enable"`
+	EnableSet  bool              `json:"-"` // Enable是否生效，为false时不修改实例的Enable；JSON中带有enable字段时为true
+}
+
+// MarshalJSON EnableSet为false时不输出enable字段
+func (config ProviderConfig) MarshalJSON() ([]byte, error) {
+	type alias ProviderConfig
+	out := struct {
+		alias
+		Enable *bool `json:"enable,omitempty"`
+	}{alias: alias(config)}
+	if config.EnableSet {
+		out.Enable = &config.Enable
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON 带有enable字段时设置EnableSet
+func (config *ProviderConfig) UnmarshalJSON(data []byte) error {
+	type alias ProviderConfig
+	in := struct {
+		*alias
+		Enable *bool `json:"enable"`
+	}{alias: (*alias)(config)}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	config.EnableSet = in.Enable != nil
+	config.Enable = config.EnableSet && *in.Enable
+	return nil
 }
 
 // ConsumerConfig config of consumer
@@ -76,6 +106,7 @@ type ConsumerConfig struct {
 	ID     string `json:"id"`
 	Scheme string `json:"scheme"`
 	Host   string `json:"host"`
+	App    string `json:"app"` // 调用方应用名
 }
 
 // RouteConfig ...
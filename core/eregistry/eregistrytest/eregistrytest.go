@@ -0,0 +1,230 @@
+// Package eregistrytest 注册中心实现需要满足的公共行为，各实现在自己的测试中调用Run
+package eregistrytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+// Timeout 等待watch推送的最长时间
+var Timeout = 3 * time.Second
+
+// Run 运行注册中心的公共测试，newRegistry每次返回一个空的注册中心。
+// 约定：
+//   - ListServices只返回name、scheme都匹配的服务提供方
+//   - 同一个实例重复注册时覆盖原有信息，重复注销不报错
+//   - WatchServices先推送一次当前的服务列表，之后只在列表变化时推送，Nodes的key为实例地址
+//   - 消费方不出现在ListServices和Nodes中，WatchServices通过ConsumerConfigs推送，key为消费方的Label
+//   - ctx取消或者注册中心关闭时关闭watch的channel
+//   - Close可以重复调用
+func Run(t *testing.T, newRegistry func(t *testing.T) eregistry.Registry) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, reg eregistry.Registry)
+	}{
+		{"RegisterAndList", testRegisterAndList},
+		{"Reregister", testReregister},
+		{"Unregister", testUnregister},
+		{"WatchInitial", testWatchInitial},
+		{"WatchUpdates", testWatchUpdates},
+		{"WatchOtherService", testWatchOtherService},
+		{"WatchCancel", testWatchCancel},
+		{"Consumers", testConsumers},
+		{"Close", testClose},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			reg := newRegistry(t)
+			defer reg.Close()
+			tt.fn(t, reg)
+		})
+	}
+}
+
+// NewService 构造测试用的服务提供方
+func NewService(name, scheme, address string) *server.ServiceInfo {
+	return &server.ServiceInfo{
+		Name:     name,
+		Scheme:   scheme,
+		Address:  address,
+		Weight:   100,
+		Enable:   true,
+		Healthy:  true,
+		Kind:     constant.ServiceProvider,
+		Metadata: map[string]string{},
+	}
+}
+
+// Receive 等待watch推送，超时或者channel关闭时测试失败
+func Receive(t *testing.T, ch chan eregistry.Endpoints) eregistry.Endpoints {
+	t.Helper()
+	select {
+	case endpoints, ok := <-ch:
+		require.True(t, ok, "watch channel closed")
+		return endpoints
+	case <-time.After(Timeout):
+		require.FailNow(t, "wait endpoints timeout")
+	}
+	return eregistry.Endpoints{}
+}
+
+// AssertClosed 断言watch的channel被关闭，关闭前收到的推送被忽略
+func AssertClosed(t *testing.T, ch chan eregistry.Endpoints) {
+	t.Helper()
+	timeout := time.After(Timeout)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			require.FailNow(t, "watch channel not closed")
+		}
+	}
+}
+
+func testRegisterAndList(t *testing.T, reg eregistry.Registry) {
+	ctx := context.Background()
+	require.NoError(t, reg.RegisterService(ctx, NewService("user", "grpc", "127.0.0.1:9001")))
+	require.NoError(t, reg.RegisterService(ctx, NewService("user", "grpc", "127.0.0.1:9002")))
+	require.NoError(t, reg.RegisterService(ctx, NewService("user", "http", "127.0.0.1:9003")))
+	require.NoError(t, reg.RegisterService(ctx, NewService("order", "grpc", "127.0.0.1:9004")))
+
+	services, err := reg.ListServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	addresses := make([]string, 0)
+	for _, info := range services {
+		assert.Equal(t, "user", info.Name)
+		assert.Equal(t, "grpc", info.Scheme)
+		addresses = append(addresses, info.Address)
+	}
+	assert.ElementsMatch(t, []string{"127.0.0.1:9001", "127.0.0.1:9002"}, addresses)
+
+	services, err = reg.ListServices(ctx, "none", "grpc")
+	require.NoError(t, err)
+	assert.Len(t, services, 0)
+}
+
+func testReregister(t *testing.T, reg eregistry.Registry) {
+	ctx := context.Background()
+	info := NewService("user", "grpc", "127.0.0.1:9001")
+	require.NoError(t, reg.RegisterService(ctx, info))
+	info.Metadata["version"] = "v2"
+	require.NoError(t, reg.RegisterService(ctx, info))
+
+	services, err := reg.ListServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "v2", services[0].Metadata["version"])
+}
+
+func testUnregister(t *testing.T, reg eregistry.Registry) {
+	ctx := context.Background()
+	info := NewService("user", "grpc", "127.0.0.1:9001")
+	require.NoError(t, reg.RegisterService(ctx, info))
+	require.NoError(t, reg.RegisterService(ctx, NewService("user", "grpc", "127.0.0.1:9002")))
+	require.NoError(t, reg.UnregisterService(ctx, info))
+	require.NoError(t, reg.UnregisterService(ctx, info))
+
+	services, err := reg.ListServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "127.0.0.1:9002", services[0].Address)
+}
+
+func testWatchInitial(t *testing.T, reg eregistry.Registry) {
+	ch, err := reg.WatchServices(context.Background(), "user", "grpc")
+	require.NoError(t, err)
+	endpoints := Receive(t, ch)
+	assert.NotNil(t, endpoints.Nodes)
+	assert.Len(t, endpoints.Nodes, 0)
+}
+
+func testWatchUpdates(t *testing.T, reg eregistry.Registry) {
+	ctx := context.Background()
+	first := NewService("user", "grpc", "127.0.0.1:9001")
+	require.NoError(t, reg.RegisterService(ctx, first))
+	ch, err := reg.WatchServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	endpoints := Receive(t, ch)
+	require.Len(t, endpoints.Nodes, 1)
+	assert.Equal(t, "user", endpoints.Nodes["127.0.0.1:9001"].Name)
+
+	require.NoError(t, reg.RegisterService(ctx, NewService("user", "grpc", "127.0.0.1:9002")))
+	endpoints = Receive(t, ch)
+	assert.Len(t, endpoints.Nodes, 2)
+	assert.Contains(t, endpoints.Nodes, "127.0.0.1:9002")
+
+	require.NoError(t, reg.UnregisterService(ctx, first))
+	endpoints = Receive(t, ch)
+	assert.Len(t, endpoints.Nodes, 1)
+	assert.NotContains(t, endpoints.Nodes, "127.0.0.1:9001")
+}
+
+func testWatchOtherService(t *testing.T, reg eregistry.Registry) {
+	ctx := context.Background()
+	ch, err := reg.WatchServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	assert.Len(t, Receive(t, ch).Nodes, 0)
+
+	// 其他服务、其他协议的变化不推送，下一次推送只包含新注册的实例
+	require.NoError(t, reg.RegisterService(ctx, NewService("order", "grpc", "127.0.0.1:9001")))
+	require.NoError(t, reg.RegisterService(ctx, NewService("user", "http", "127.0.0.1:9002")))
+	require.NoError(t, reg.RegisterService(ctx, NewService("user", "grpc", "127.0.0.1:9003")))
+	endpoints := Receive(t, ch)
+	require.Len(t, endpoints.Nodes, 1)
+	assert.Contains(t, endpoints.Nodes, "127.0.0.1:9003")
+}
+
+func testWatchCancel(t *testing.T, reg eregistry.Registry) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := reg.WatchServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	Receive(t, ch)
+	cancel()
+	AssertClosed(t, ch)
+}
+
+func testConsumers(t *testing.T, reg eregistry.Registry) {
+	ctx := context.Background()
+	require.NoError(t, reg.RegisterService(ctx, NewService("user", "grpc", "127.0.0.1:9001")))
+	consumer := NewService("user", "grpc", "order-host:1234")
+	consumer.Kind = constant.ServiceConsumer
+	consumer.Metadata[eregistry.MetadataConsumerApp] = "order"
+	require.NoError(t, reg.RegisterService(ctx, consumer))
+
+	services, err := reg.ListServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "127.0.0.1:9001", services[0].Address)
+
+	ch, err := reg.WatchServices(ctx, "user", "grpc")
+	require.NoError(t, err)
+	endpoints := Receive(t, ch)
+	assert.Len(t, endpoints.Nodes, 1)
+	assert.Equal(t, map[string]eregistry.ConsumerConfig{
+		"grpc://order-host:1234": {ID: "grpc://order-host:1234", Scheme: "grpc", Host: "order-host:1234", App: "order"},
+	}, endpoints.ConsumerConfigs)
+
+	require.NoError(t, reg.UnregisterService(ctx, consumer))
+	assert.Len(t, Receive(t, ch).ConsumerConfigs, 0)
+}
+
+func testClose(t *testing.T, reg eregistry.Registry) {
+	ch, err := reg.WatchServices(context.Background(), "user", "grpc")
+	require.NoError(t, err)
+	Receive(t, ch)
+	require.NoError(t, reg.Close())
+	AssertClosed(t, ch)
+	require.NoError(t, reg.Close())
+}
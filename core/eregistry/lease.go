@@ -0,0 +1,316 @@
+package eregistry
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/util/xgo"
+	"github.com/gotomicro/ego/server"
+)
+
+// ErrNotRegistered 续约时实例已不在注册中心，例如注册中心重启或者租约已过期
+var ErrNotRegistered = errors.New("service not registered")
+
+// DefaultLeaseInterval 注册中心没有实现KeepAliver时，检查实例是否还在注册中心的间隔
+const DefaultLeaseInterval = 30 * time.Second
+
+// KeepAliver 基于租约的注册中心实现该接口，Lease按KeepAliveInterval续约
+type KeepAliver interface {
+	// KeepAlive 续约，实例已不在注册中心时返回ErrNotRegistered
+	KeepAlive(ctx context.Context, info *server.ServiceInfo) error
+	// KeepAliveInterval 续约间隔，需要小于租约有效期
+	KeepAliveInterval() time.Duration
+}
+
+// LeaseState 实例的注册状态
+type LeaseState struct {
+	Name       string    `json:"name"`
+	Scheme     string    `json:"scheme"`
+	Address    string    `json:"address"`
+	Kind       string    `json:"kind"` // providers或者consumers
+	Registered bool      `json:"registered"`
+	Error      string    `json:"error,omitempty"` // 最近一次续约或注册失败的原因
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// LeaseOption Lease选项
+type LeaseOption func(l *Lease)
+
+// WithLeaseInterval 设置续约或检查的间隔，默认使用KeepAliver.KeepAliveInterval或DefaultLeaseInterval
+func WithLeaseInterval(interval time.Duration) LeaseOption {
+	return func(l *Lease) {
+		l.interval = interval
+	}
+}
+
+// Lease 包装注册中心，注册后定期续约；注册失败或者发现注册丢失(网络分区、注册中心重启)时重新注册
+type Lease struct {
+	Registry
+	interval time.Duration
+	logger   *elog.Component
+	mu       sync.Mutex
+	leases   map[string]*lease
+}
+
+type lease struct {
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	regMu  sync.Mutex          // 串行化续约中的重新注册与注销，避免注销后又被注册回去
+	info   *server.ServiceInfo // 应用ProviderConfig后会替换
+	state  LeaseState
+}
+
+var (
+	// activeLeases 所有未关闭的Lease，governor通过LeaseStates读取
+	activeLeases   = make(map[*Lease]struct{})
+	activeLeasesMu sync.Mutex
+)
+
+// NewLease 包装注册中心，已经是Lease时直接返回
+func NewLease(reg Registry, options ...LeaseOption) *Lease {
+	if l, ok := reg.(*Lease); ok {
+		return l
+	}
+	l := &Lease{
+		Registry: reg,
+		interval: DefaultLeaseInterval,
+		logger:   elog.EgoLogger.With(elog.FieldComponent("registry")),
+		leases:   make(map[string]*lease),
+	}
+	if keepAliver, ok := reg.(KeepAliver); ok {
+		l.interval = keepAliver.KeepAliveInterval()
+	}
+	for _, option := range options {
+		option(l)
+	}
+	activeLeasesMu.Lock()
+	activeLeases[l] = struct{}{}
+	activeLeasesMu.Unlock()
+	return l
+}
+
+// LeaseStates 返回所有实例的注册状态，按地址排序
+func LeaseStates() []LeaseState {
+	activeLeasesMu.Lock()
+	defer activeLeasesMu.Unlock()
+	states := make([]LeaseState, 0)
+	for l := range activeLeases {
+		states = append(states, l.States()...)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name+states[i].Address < states[j].Name+states[j].Address
+	})
+	return states
+}
+
+// RegisterService 注册实例并开始续约，首次注册失败时返回错误，之后仍会按间隔重试
+func (l *Lease) RegisterService(ctx context.Context, info *server.ServiceInfo) error {
+	err := l.Registry.RegisterService(ctx, info)
+	ls := &lease{info: info}
+	ls.set(err)
+
+	key := GetServiceKey("lease", info)
+	keepCtx, cancel := context.WithCancel(context.Background())
+	ls.cancel = cancel
+	l.mu.Lock()
+	if old, ok := l.leases[key]; ok {
+		old.cancel()
+	}
+	l.leases[key] = ls
+	l.mu.Unlock()
+	xgo.Go(func() {
+		l.keep(keepCtx, ls)
+	})
+	if info.Kind == constant.ServiceProvider {
+		xgo.Go(func() {
+			l.watchConfig(keepCtx, ls)
+		})
+	}
+	return err
+}
+
+// UnregisterService 停止续约并注销实例
+func (l *Lease) UnregisterService(ctx context.Context, info *server.ServiceInfo) error {
+	key := GetServiceKey("lease", info)
+	l.mu.Lock()
+	ls, ok := l.leases[key]
+	if ok {
+		ls.cancel()
+		delete(l.leases, key)
+	}
+	l.mu.Unlock()
+	// 等待进行中的重新注册结束，之后的续约已停止，不会再注册
+	if ok {
+		ls.regMu.Lock()
+		defer ls.regMu.Unlock()
+	}
+	server.SetOverride(info.Label(), nil)
+	emetric.RegistryStateGauge.DeleteLabelValues(info.Name, info.Scheme, info.Address)
+	return l.Registry.UnregisterService(ctx, info)
+}
+
+// States 返回当前续约中实例的注册状态
+func (l *Lease) States() []LeaseState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	states := make([]LeaseState, 0, len(l.leases))
+	for _, ls := range l.leases {
+		ls.mu.Lock()
+		states = append(states, ls.state)
+		ls.mu.Unlock()
+	}
+	return states
+}
+
+// Close 停止所有续约并关闭注册中心，停止续约后可以继续注销实例
+func (l *Lease) Close() error {
+	l.stop()
+	return l.Registry.Close()
+}
+
+// stop 停止所有续约，不关闭注册中心
+func (l *Lease) stop() {
+	l.mu.Lock()
+	for _, ls := range l.leases {
+		ls.cancel()
+	}
+	l.mu.Unlock()
+	activeLeasesMu.Lock()
+	delete(activeLeases, l)
+	activeLeasesMu.Unlock()
+}
+
+// infos 返回续约中的实例
+func (l *Lease) infos() []*server.ServiceInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	infos := make([]*server.ServiceInfo, 0, len(l.leases))
+	for _, ls := range l.leases {
+		infos = append(infos, ls.current())
+	}
+	return infos
+}
+
+func (l *Lease) keep(ctx context.Context, ls *lease) {
+	if l.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.renew(ctx, ls)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// renew 续约，注册丢失或者之前注册失败时重新注册
+func (l *Lease) renew(ctx context.Context, ls *lease) {
+	info := ls.current()
+	if ls.registered() {
+		err := l.keepAlive(ctx, info)
+		if err == nil {
+			ls.set(nil)
+			return
+		}
+		if !errors.Is(err, ErrNotRegistered) {
+			l.logger.Error("keepalive service", elog.FieldErr(err), elog.FieldName(info.Name), elog.FieldAddr(info.Label()))
+			ls.set(err)
+			return
+		}
+		l.logger.Warn("service registration lost", elog.FieldName(info.Name), elog.FieldAddr(info.Label()))
+	}
+	err := l.register(ctx, ls, info)
+	if ctx.Err() != nil {
+		return
+	}
+	ls.set(err)
+	if err != nil {
+		l.logger.Error("reregister service", elog.FieldErr(err), elog.FieldName(info.Name), elog.FieldAddr(info.Label()))
+		return
+	}
+	emetric.RegistryReregisterCounter.Inc(info.Name, info.Scheme, info.Address)
+	l.logger.Info("reregister service", elog.FieldName(info.Name), elog.FieldAddr(info.Label()))
+}
+
+// register 续约过程中重新注册，与UnregisterService互斥，停止续约后不再注册
+func (l *Lease) register(ctx context.Context, ls *lease, info *server.ServiceInfo) error {
+	ls.regMu.Lock()
+	defer ls.regMu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.Registry.RegisterService(ctx, info)
+}
+
+// keepAlive 续约或者检查实例是否还在注册中心
+func (l *Lease) keepAlive(ctx context.Context, info *server.ServiceInfo) error {
+	return KeepAlive(ctx, l.Registry, info)
+}
+
+// KeepAlive 注册中心实现了KeepAliver时续约，否则通过ListServices检查服务提供方是否还在，
+// 实例已不在注册中心时返回ErrNotRegistered
+func KeepAlive(ctx context.Context, reg Registry, info *server.ServiceInfo) error {
+	if keepAliver, ok := reg.(KeepAliver); ok {
+		return keepAliver.KeepAlive(ctx, info)
+	}
+	if info.Kind != constant.ServiceProvider {
+		return nil
+	}
+	services, err := reg.ListServices(ctx, info.Name, info.Scheme)
+	if err != nil {
+		return err
+	}
+	for _, service := range services {
+		if service.Address == info.Address {
+			return nil
+		}
+	}
+	return ErrNotRegistered
+}
+
+func (ls *lease) current() *server.ServiceInfo {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.info
+}
+
+func (ls *lease) update(info *server.ServiceInfo) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.info = info
+}
+
+func (ls *lease) registered() bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.state.Registered
+}
+
+func (ls *lease) set(err error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.state = LeaseState{
+		Name:       ls.info.Name,
+		Scheme:     ls.info.Scheme,
+		Address:    ls.info.Address,
+		Kind:       ls.info.Kind.String(),
+		Registered: err == nil,
+		UpdatedAt:  time.Now(),
+	}
+	value := 1.0
+	if err != nil {
+		ls.state.Error = err.Error()
+		value = 0
+	}
+	emetric.RegistryStateGauge.Set(value, ls.info.Name, ls.info.Scheme, ls.info.Address)
+}
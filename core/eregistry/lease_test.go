@@ -0,0 +1,309 @@
+package eregistry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/server"
+)
+
+// mapRegistry 没有实现KeepAliver的注册中心，fail不为空时注册失败
+type mapRegistry struct {
+	Nop
+	mu       sync.Mutex
+	services map[string]*server.ServiceInfo
+	fail     error
+}
+
+func (m *mapRegistry) RegisterService(ctx context.Context, info *server.ServiceInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail != nil {
+		return m.fail
+	}
+	m.services[info.Address] = info
+	return nil
+}
+
+func (m *mapRegistry) UnregisterService(ctx context.Context, info *server.ServiceInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.services, info.Address)
+	return nil
+}
+
+func (m *mapRegistry) ListServices(ctx context.Context, name string, scheme string) ([]*server.ServiceInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	services := make([]*server.ServiceInfo, 0)
+	for _, info := range m.services {
+		services = append(services, info)
+	}
+	return services, nil
+}
+
+func (m *mapRegistry) setFail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fail = err
+}
+
+func (m *mapRegistry) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.services)
+}
+
+func TestLease(t *testing.T) {
+	reg := &mapRegistry{services: make(map[string]*server.ServiceInfo)}
+	lease := NewLease(reg, WithLeaseInterval(10*time.Millisecond))
+	assert.Equal(t, lease, NewLease(lease))
+	info := &server.ServiceInfo{Name: "user", Scheme: "grpc", Address: "127.0.0.1:9001", Kind: constant.ServiceProvider}
+	ctx := context.Background()
+
+	// 首次注册失败返回错误，之后重试成功
+	reg.setFail(errors.New("registry unavailable"))
+	require.Error(t, lease.RegisterService(ctx, info))
+	states := LeaseStates()
+	require.Len(t, states, 1)
+	assert.False(t, states[0].Registered)
+	assert.Equal(t, "registry unavailable", states[0].Error)
+	assert.Equal(t, float64(0), testutil.ToFloat64(emetric.RegistryStateGauge.WithLabelValues("user", "grpc", "127.0.0.1:9001")))
+
+	reg.setFail(nil)
+	assert.Eventually(t, func() bool {
+		return reg.len() == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return lease.States()[0].Registered
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.RegistryStateGauge.WithLabelValues("user", "grpc", "127.0.0.1:9001")))
+
+	// 注册丢失后重新注册
+	before := testutil.ToFloat64(emetric.RegistryReregisterCounter.WithLabelValues("user", "grpc", "127.0.0.1:9001"))
+	require.NoError(t, reg.UnregisterService(ctx, info))
+	assert.Eventually(t, func() bool {
+		return reg.len() == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(emetric.RegistryReregisterCounter.WithLabelValues("user", "grpc", "127.0.0.1:9001")) > before
+	}, time.Second, 5*time.Millisecond)
+
+	// 关闭之后停止续约，不再出现在LeaseStates中
+	require.NoError(t, lease.Close())
+	require.NoError(t, lease.UnregisterService(ctx, info))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, reg.len())
+	assert.Len(t, LeaseStates(), 0)
+}
+
+// blockingRegistry blocked设置为1后，下一次注册阻塞到release关闭
+type blockingRegistry struct {
+	*mapRegistry
+	blocked int32
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingRegistry) RegisterService(ctx context.Context, info *server.ServiceInfo) error {
+	if atomic.CompareAndSwapInt32(&b.blocked, 1, 2) {
+		close(b.entered)
+		<-b.release
+	}
+	return b.mapRegistry.RegisterService(ctx, info)
+}
+
+func TestLeaseUnregisterDuringReregister(t *testing.T) {
+	reg := &blockingRegistry{
+		mapRegistry: &mapRegistry{services: make(map[string]*server.ServiceInfo)},
+		entered:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	lease := NewLease(reg, WithLeaseInterval(10*time.Millisecond))
+	defer lease.Close()
+	info := &server.ServiceInfo{Name: "user", Scheme: "grpc", Address: "127.0.0.1:9002", Kind: constant.ServiceProvider}
+	ctx := context.Background()
+	require.NoError(t, lease.RegisterService(ctx, info))
+
+	// 注册丢失，续约时重新注册并阻塞
+	atomic.StoreInt32(&reg.blocked, 1)
+	require.NoError(t, reg.mapRegistry.UnregisterService(ctx, info))
+	<-reg.entered
+
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, lease.UnregisterService(ctx, info))
+		close(done)
+	}()
+	// 注销等待进行中的重新注册完成，最终不会留下实例
+	select {
+	case <-done:
+		t.Fatal("unregister finished before reregister")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(reg.release)
+	<-done
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0, reg.len())
+}
+
+// WatchServices 只推送一次当前的服务列表
+func (m *mapRegistry) WatchServices(ctx context.Context, name string, scheme string) (chan Endpoints, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	endpoints := NewEndpoints()
+	for _, info := range m.services {
+		if info.Name != name || info.Scheme != scheme {
+			continue
+		}
+		if info.Kind == constant.ServiceConsumer {
+			config := NewConsumerConfig(*info)
+			endpoints.ConsumerConfigs[config.ID] = config
+		} else {
+			endpoints.Nodes[info.Address] = *info
+		}
+	}
+	ch := make(chan Endpoints, 1)
+	ch <- *endpoints
+	return ch, nil
+}
+
+func TestTopology(t *testing.T) {
+	reg := &mapRegistry{services: make(map[string]*server.ServiceInfo)}
+	ctx := context.Background()
+	provider := NewLease(reg)
+	defer provider.Close()
+	require.NoError(t, provider.RegisterService(ctx, &server.ServiceInfo{Name: "user", Scheme: "grpc", Address: "127.0.0.1:9001", Kind: constant.ServiceProvider}))
+	// 其他应用注册为user的消费方
+	remote := &server.ServiceInfo{Name: "user", Scheme: "grpc", Address: "order-host:1234", Kind: constant.ServiceConsumer, Metadata: map[string]string{MetadataConsumerApp: "order"}}
+	require.NoError(t, reg.RegisterService(ctx, remote))
+
+	Register("topology", reg)
+	require.NoError(t, RegisterConsumer(ctx, "topology", "payment", "grpc"))
+	assert.Equal(t, 3, reg.len())
+
+	topology := GetTopology(ctx)
+	require.Len(t, topology.Calls, 1)
+	assert.Equal(t, "payment", topology.Calls[0].Name)
+	assert.Equal(t, "consumers", topology.Calls[0].Kind)
+	assert.True(t, topology.Calls[0].Registered)
+	assert.Equal(t, map[string][]ConsumerConfig{
+		"user": {{ID: "grpc://order-host:1234", Scheme: "grpc", Host: "order-host:1234", App: "order"}},
+	}, topology.CalledBy)
+
+	// 注销消费方不影响服务提供方的注册
+	require.NoError(t, UnregisterConsumers())
+	assert.Equal(t, 2, reg.len())
+	assert.Len(t, GetTopology(ctx).Calls, 0)
+	assert.Len(t, LeaseStates(), 1)
+}
+
+// unwatchableRegistry WatchServices使用Nop的实现，调用时panic
+type unwatchableRegistry struct {
+	*mapRegistry
+}
+
+func (u unwatchableRegistry) WatchServices(ctx context.Context, name string, scheme string) (chan Endpoints, error) {
+	return Nop{}.WatchServices(ctx, name, scheme)
+}
+
+func TestLeaseWatchUnsupported(t *testing.T) {
+	reg := &mapRegistry{services: make(map[string]*server.ServiceInfo)}
+	lease := NewLease(unwatchableRegistry{reg}, WithLeaseInterval(10*time.Millisecond))
+	defer lease.Close()
+	info := &server.ServiceInfo{Name: "user", Scheme: "grpc", Address: "127.0.0.1:9001", Kind: constant.ServiceProvider, Enable: true}
+
+	// 不支持watch时仍然注册和续约，不下发配置
+	require.NoError(t, lease.RegisterService(context.Background(), info))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, reg.len())
+	require.NoError(t, lease.UnregisterService(context.Background(), info))
+}
+
+// flakyWatchRegistry 第一次watch失败，第二次推送关闭实例的配置后关闭channel，之后推送空配置
+type flakyWatchRegistry struct {
+	*mapRegistry
+	calls    int32
+	disabled int32 // 重新watch时实例已关闭
+}
+
+func (f *flakyWatchRegistry) WatchServices(ctx context.Context, name string, scheme string) (chan Endpoints, error) {
+	endpoints := NewEndpoints()
+	switch atomic.AddInt32(&f.calls, 1) {
+	case 1:
+		return nil, errors.New("registry unavailable")
+	case 2:
+		endpoints.ProviderConfigs["offline"] = ProviderConfig{ID: "offline", EnableSet: true}
+		ch := make(chan Endpoints, 1)
+		ch <- *endpoints
+		close(ch)
+		return ch, nil
+	case 3:
+		if !f.enabled("127.0.0.1:9002") {
+			atomic.StoreInt32(&f.disabled, 1)
+		}
+	}
+	ch := make(chan Endpoints, 1)
+	ch <- *endpoints
+	return ch, nil
+}
+
+func (f *flakyWatchRegistry) enabled(address string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, ok := f.services[address]
+	return ok && info.Enable
+}
+
+func TestLeaseWatchConfigRetry(t *testing.T) {
+	minWatchBackoff = 10 * time.Millisecond
+	defer func() {
+		minWatchBackoff = time.Second
+	}()
+	reg := &flakyWatchRegistry{mapRegistry: &mapRegistry{services: make(map[string]*server.ServiceInfo)}}
+	lease := NewLease(reg, WithLeaseInterval(time.Minute))
+	defer lease.Close()
+	info := &server.ServiceInfo{Name: "user", Scheme: "grpc", Address: "127.0.0.1:9002", Kind: constant.ServiceProvider, Enable: true}
+	require.NoError(t, lease.RegisterService(context.Background(), info))
+
+	// watch失败后重试，收到关闭实例的配置；channel关闭后重新watch，配置删除后恢复
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&reg.calls) >= 3 && reg.enabled(info.Address)
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reg.disabled))
+	require.NoError(t, lease.UnregisterService(context.Background(), info))
+}
+
+// uncomparableRegistry 动态类型不可比较的注册中心，不能作为map的key
+type uncomparableRegistry struct {
+	*mapRegistry
+	tags []string
+}
+
+func TestRegisterConsumerUncomparable(t *testing.T) {
+	reg := &mapRegistry{services: make(map[string]*server.ServiceInfo)}
+	Register("uncomparable", uncomparableRegistry{mapRegistry: reg, tags: []string{"a"}})
+	ctx := context.Background()
+
+	require.NoError(t, RegisterConsumer(ctx, "uncomparable", "user", "grpc"))
+	require.NoError(t, RegisterConsumer(ctx, "uncomparable", "order", "grpc"))
+	// 同一个注册中心复用一个Lease
+	consumerLeasesMu.Lock()
+	assert.Len(t, consumerLeases["uncomparable"].infos(), 2)
+	consumerLeasesMu.Unlock()
+	assert.Equal(t, 1, reg.len())
+	assert.Error(t, RegisterConsumer(ctx, "unknown", "user", "grpc"))
+
+	require.NoError(t, UnregisterConsumers())
+	assert.Equal(t, 0, reg.len())
+}
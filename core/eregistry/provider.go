@@ -0,0 +1,148 @@
+package eregistry
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
+)
+
+// Match 配置是否作用于该实例，Scheme、Host为空时作用于服务的所有实例
+func (config ProviderConfig) Match(info *server.ServiceInfo) bool {
+	return (config.Scheme == "" || config.Scheme == info.Scheme) && (config.Host == "" || config.Host == info.Address)
+}
+
+// Apply 修改服务信息，Enable在EnableSet时生效，Deployment、Region、Zone不为空时生效，Metadata合并到原有的Metadata中
+func (config ProviderConfig) Apply(info *server.ServiceInfo) {
+	if config.EnableSet {
+		info.Enable = config.Enable
+	}
+	if config.Deployment != "" {
+		info.Deployment = config.Deployment
+	}
+	if config.Region != "" {
+		info.Region = config.Region
+	}
+	if config.Zone != "" {
+		info.Zone = config.Zone
+	}
+	if len(config.Metadata) > 0 && info.Metadata == nil {
+		info.Metadata = make(map[string]string, len(config.Metadata))
+	}
+	for k, v := range config.Metadata {
+		info.Metadata[k] = v
+	}
+}
+
+// matchProviderConfigs 作用于该实例的配置，服务级别的配置在前，实例级别的配置在后，后面的覆盖前面的
+func matchProviderConfigs(configs map[string]ProviderConfig, info *server.ServiceInfo) []ProviderConfig {
+	var matched []ProviderConfig
+	for _, config := range configs {
+		if config.Match(info) {
+			matched = append(matched, config)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if (matched[i].Host == "") != (matched[j].Host == "") {
+			return matched[i].Host == ""
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	return matched
+}
+
+var (
+	// minWatchBackoff、maxWatchBackoff watch失败或者channel关闭后重新watch的退避时间
+	minWatchBackoff = time.Second
+	maxWatchBackoff = 30 * time.Second
+)
+
+// watchConfig 监听注册中心下发的ProviderConfigs，变化时更新Info()返回的服务信息并重新注册，
+// 例如enable设置为false后，调用方的负载均衡不再选择该实例。watch失败或者channel关闭后退避重试，直到停止续约
+func (l *Lease) watchConfig(ctx context.Context, ls *lease) {
+	base := *ls.current()
+	var last []ProviderConfig
+	var backoff time.Duration
+	for {
+		received, supported := l.watchConfigOnce(ctx, ls, base, &last)
+		if !supported || ctx.Err() != nil {
+			return
+		}
+		// 收到过推送说明注册中心可用，从最小退避时间开始
+		if received {
+			backoff = 0
+		}
+		backoff *= 2
+		if backoff < minWatchBackoff {
+			backoff = minWatchBackoff
+		}
+		if backoff > maxWatchBackoff {
+			backoff = maxWatchBackoff
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchConfigOnce watch一次直到channel关闭，返回是否收到过推送，注册中心不支持watch时supported为false
+func (l *Lease) watchConfigOnce(ctx context.Context, ls *lease, base server.ServiceInfo, last *[]ProviderConfig) (received bool, supported bool) {
+	// 不支持watch的注册中心(例如Nop)可能panic，此时不下发配置
+	defer func() {
+		if r := recover(); r != nil {
+			l.logger.Warn("watch provider config unsupported", elog.FieldName(base.Name), elog.FieldAddr(base.Label()), elog.FieldValueAny(r))
+			supported = false
+		}
+	}()
+	endpoints, err := l.Registry.WatchServices(ctx, base.Name, base.Scheme)
+	if err != nil {
+		l.logger.Error("watch provider config", elog.FieldErr(err), elog.FieldName(base.Name), elog.FieldAddr(base.Label()))
+		return false, true
+	}
+	for endpoint := range endpoints {
+		received = true
+		configs := matchProviderConfigs(endpoint.ProviderConfigs, &base)
+		if reflect.DeepEqual(configs, *last) {
+			continue
+		}
+		*last = configs
+		info, err := l.applyConfigs(ctx, ls, base, configs)
+		if ctx.Err() != nil {
+			return received, true
+		}
+		if err != nil {
+			l.logger.Error("apply provider config", elog.FieldErr(err), elog.FieldName(info.Name), elog.FieldAddr(info.Label()))
+			continue
+		}
+		l.logger.Info("apply provider config", elog.FieldName(info.Name), elog.FieldAddr(info.Label()), elog.FieldValueAny(configs))
+	}
+	return received, true
+}
+
+// applyConfigs 设置运行时修改并重新注册，与UnregisterService互斥，注销后不再修改和注册
+func (l *Lease) applyConfigs(ctx context.Context, ls *lease, base server.ServiceInfo, configs []ProviderConfig) (*server.ServiceInfo, error) {
+	ls.regMu.Lock()
+	defer ls.regMu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return &base, err
+	}
+	if len(configs) == 0 {
+		server.SetOverride(base.Label(), nil)
+	} else {
+		server.SetOverride(base.Label(), func(info *server.ServiceInfo) {
+			for _, config := range configs {
+				config.Apply(info)
+			}
+		})
+	}
+	info := server.ApplyOverride(base)
+	ls.update(info)
+	err := l.Registry.RegisterService(ctx, info)
+	ls.set(err)
+	return info, err
+}
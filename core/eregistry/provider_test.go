@@ -0,0 +1,36 @@
+package eregistry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/server"
+)
+
+func TestProviderConfigJSON(t *testing.T) {
+	// 没有设置Enable时不输出enable，反序列化后仍然不修改实例的Enable
+	data, err := json.Marshal(ProviderConfig{ID: "version", Metadata: map[string]string{"version": "v2"}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), `"enable"`)
+	var config ProviderConfig
+	require.NoError(t, json.Unmarshal(data, &config))
+	assert.False(t, config.EnableSet)
+	info := &server.ServiceInfo{Enable: true}
+	config.Apply(info)
+	assert.True(t, info.Enable)
+	assert.Equal(t, "v2", info.Metadata["version"])
+
+	// 控制面只下发enable字段
+	config = ProviderConfig{}
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"offline","enable":false}`), &config))
+	assert.Equal(t, ProviderConfig{ID: "offline", EnableSet: true}, config)
+	config.Apply(info)
+	assert.False(t, info.Enable)
+
+	data, err = json.Marshal(config)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"enable":false`)
+}
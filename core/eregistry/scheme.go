@@ -0,0 +1,23 @@
+package eregistry
+
+import "sync"
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = make(map[string]Registry)
+)
+
+// Register 按名称注册服务发现使用的注册中心，egrpc、ehttp的地址为{name}:///{服务名}时通过该注册中心发现节点
+func Register(name string, reg Registry) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	schemes[name] = reg
+}
+
+// Get 返回通过Register注册的注册中心
+func Get(name string) (Registry, bool) {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	reg, ok := schemes[name]
+	return reg, ok
+}
@@ -2,6 +2,8 @@ package etrace
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -47,20 +49,21 @@ func FromIncomingContext(ctx context.Context) opentracing.StartSpanOption {
 	if !ok {
 		md = metadata.New(nil)
 	}
-	sc, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, MetadataReaderWriter{MD: md})
+	hdr := metadataToHeader(md)
+	sc, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, hdr)
 	if err != nil {
-		return NullStartSpanOption{}
+		return forceSampleOption(hdr)
 	}
-	return ext.RPCServerOption(sc)
+	return startSpanOptions{ext.RPCServerOption(sc), forceSampleOption(hdr)}
 }
 
 // HeaderExtractor ...
 func HeaderExtractor(hdr map[string][]string) opentracing.StartSpanOption {
-	sc, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, MetadataReaderWriter{MD: hdr})
+	sc, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(hdr))
 	if err != nil {
-		return NullStartSpanOption{}
+		return forceSampleOption(hdr)
 	}
-	return opentracing.ChildOf(sc)
+	return startSpanOptions{opentracing.ChildOf(sc), forceSampleOption(hdr)}
 }
 
 type hdrRequestKey struct{}
@@ -68,7 +71,7 @@ type hdrRequestKey struct{}
 // HeaderInjector ...
 func HeaderInjector(ctx context.Context, hdr map[string][]string) context.Context {
 	span := opentracing.SpanFromContext(ctx)
-	err := opentracing.GlobalTracer().Inject(span.Context(), opentracing.HTTPHeaders, MetadataReaderWriter{MD: hdr})
+	err := opentracing.GlobalTracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(hdr))
 	if err != nil {
 		span.LogFields(log.String("event", "inject failed"), log.Error(err))
 		return ctx
@@ -78,26 +81,59 @@ func HeaderInjector(ctx context.Context, hdr map[string][]string) context.Contex
 
 // MetadataExtractor ...
 func MetadataExtractor(md map[string][]string) opentracing.StartSpanOption {
-	sc, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, MetadataReaderWriter{MD: md})
+	hdr := metadataToHeader(md)
+	sc, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, hdr)
 	if err != nil {
-		return NullStartSpanOption{}
+		return forceSampleOption(hdr)
 	}
-	return opentracing.ChildOf(sc)
+	return startSpanOptions{opentracing.ChildOf(sc), forceSampleOption(hdr)}
 }
 
 // MetadataInjector ...
 func MetadataInjector(ctx context.Context, md metadata.MD) context.Context {
 	span := opentracing.SpanFromContext(ctx)
-	err := opentracing.GlobalTracer().Inject(span.Context(), opentracing.HTTPHeaders, MetadataReaderWriter{MD: md})
+	hdr := make(http.Header)
+	err := opentracing.GlobalTracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(hdr))
 	if err != nil {
 		span.LogFields(log.String("event", "inject failed"), log.Error(err))
 		return ctx
 	}
+	headerToMetadata(hdr, md)
 	return metadata.NewOutgoingContext(ctx, md)
 }
 
+// metadataToHeader gRPC metadata的key都是小写，转换成http.Header规范化key，
+// 保证按http.Header读取的tracer(例如opentelemetry)也能取到
+func metadataToHeader(md map[string][]string) opentracing.HTTPHeadersCarrier {
+	hdr := make(http.Header, len(md))
+	for k, vals := range md {
+		for _, v := range vals {
+			hdr.Add(k, v)
+		}
+	}
+	return opentracing.HTTPHeadersCarrier(hdr)
+}
+
+// headerToMetadata 将http.Header写回gRPC metadata，key转为小写
+func headerToMetadata(hdr http.Header, md map[string][]string) {
+	for k, vals := range hdr {
+		k = strings.ToLower(k)
+		md[k] = append(md[k], vals...)
+	}
+}
+
 // NullStartSpanOption ...
 type NullStartSpanOption struct{}
 
 // Apply ...
 func (sso NullStartSpanOption) Apply(options *opentracing.StartSpanOptions) {}
+
+// startSpanOptions 组合多个StartSpanOption
+type startSpanOptions []opentracing.StartSpanOption
+
+// Apply ...
+func (opts startSpanOptions) Apply(options *opentracing.StartSpanOptions) {
+	for _, opt := range opts {
+		opt.Apply(options)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gotomicro/ego/core/eapp"
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/etrace"
 )
 
 // Config ...
@@ -19,9 +20,11 @@ type Config struct {
 	Sampler          *jconfig.SamplerConfig
 	Reporter         *jconfig.ReporterConfig
 	Headers          *jaeger.HeadersConfig
+	Propagators      []string // 链路传递格式，按顺序组合，可选[jaeger|tracecontext|baggage|b3|b3multi]，默认[jaeger]
 	EnableRPCMetrics bool
 	tags             []opentracing.Tag
 	options          []jconfig.Option
+	sampler          *etrace.Sampler
 	PanicOnError     bool
 	closer           func() error
 }
@@ -58,6 +61,7 @@ func DefaultConfig() *Config {
 			TraceBaggageHeaderPrefix: "ctx-",
 			TraceContextHeaderName:   headerName,
 		},
+		Propagators: []string{etrace.PropagatorJaeger},
 		tags: []opentracing.Tag{
 			{Key: "hostname", Value: eapp.HostName()},
 		},
@@ -83,6 +87,12 @@ func (config *Config) WithOption(options ...jconfig.Option) *Config {
 	return config
 }
 
+// WithSampler 使用etrace.Sampler采样，配置变更时无需重建tracer，覆盖Sampler配置
+func (config *Config) WithSampler(sampler *etrace.Sampler) *Config {
+	config.sampler = sampler
+	return config
+}
+
 // Build ...
 func (config *Config) Build(options ...jconfig.Option) opentracing.Tracer {
 	var configuration = jconfig.Configuration{
@@ -93,7 +103,26 @@ func (config *Config) Build(options ...jconfig.Option) opentracing.Tracer {
 		Headers:     config.Headers,
 		Tags:        config.tags,
 	}
-	tracer, closer, err := configuration.NewTracer(config.options...)
+	options = append(config.options, options...)
+	if config.sampler != nil {
+		options = append(options, jconfig.Sampler(&jaegerSampler{sampler: config.sampler}))
+	}
+	propagator, err := newPropagator(config.Propagators, config.Headers)
+	if err != nil {
+		if config.PanicOnError {
+			elog.Panic("new jaeger propagator", elog.FieldComponent("jaeger"), elog.FieldErr(err))
+		} else {
+			elog.Error("new jaeger propagator", elog.FieldComponent("jaeger"), elog.FieldErr(err))
+		}
+	} else {
+		options = append(options,
+			jconfig.Injector(opentracing.HTTPHeaders, propagator),
+			jconfig.Extractor(opentracing.HTTPHeaders, propagator),
+			jconfig.Injector(opentracing.TextMap, propagator),
+			jconfig.Extractor(opentracing.TextMap, propagator),
+		)
+	}
+	tracer, closer, err := configuration.NewTracer(options...)
 	if err != nil {
 		if config.PanicOnError {
 			elog.Panic("new jaeger", elog.FieldComponent("jaeger"), elog.FieldErr(err))
@@ -0,0 +1,232 @@
+package ejaeger
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/zipkin"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+const (
+	traceParentHeader = "traceparent"
+	b3SingleHeader    = "b3"
+	baggageHeader     = "baggage"
+)
+
+// propagator 同时实现jaeger.Injector、jaeger.Extractor
+type propagator interface {
+	jaeger.Injector
+	jaeger.Extractor
+}
+
+// newPropagator 按配置顺序组合propagator
+func newPropagator(names []string, headers *jaeger.HeadersConfig) (propagator, error) {
+	propagators := make(compositePropagator, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case etrace.PropagatorTraceContext:
+			propagators = append(propagators, traceContextPropagator{})
+		case etrace.PropagatorBaggage:
+			propagators = append(propagators, baggagePropagator{})
+		case etrace.PropagatorB3:
+			propagators = append(propagators, b3SinglePropagator{})
+		case etrace.PropagatorB3Multi:
+			propagators = append(propagators, zipkin.NewZipkinB3HTTPHeaderPropagator())
+		case etrace.PropagatorJaeger:
+			propagators = append(propagators, jaeger.NewHTTPHeaderPropagator(headers, *jaeger.NewNullMetrics()))
+		default:
+			return nil, fmt.Errorf("unsupported propagator: %s", name)
+		}
+	}
+	return propagators, nil
+}
+
+// compositePropagator 注入时写入全部格式，提取时使用第一个提取出的span，并合并所有格式中的baggage
+type compositePropagator []propagator
+
+// Inject ...
+func (p compositePropagator) Inject(sc jaeger.SpanContext, carrier interface{}) error {
+	for _, i := range p {
+		if err := i.Inject(sc, carrier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Extract ...
+func (p compositePropagator) Extract(carrier interface{}) (jaeger.SpanContext, error) {
+	var (
+		found   jaeger.SpanContext
+		baggage = make(map[string]string)
+	)
+	for _, i := range p {
+		sc, err := i.Extract(carrier)
+		if err != nil {
+			continue
+		}
+		sc.ForeachBaggageItem(func(k, v string) bool {
+			baggage[k] = v
+			return true
+		})
+		if !found.IsValid() && sc.IsValid() {
+			found = sc
+		}
+	}
+	if !found.IsValid() {
+		return jaeger.SpanContext{}, opentracing.ErrSpanContextNotFound
+	}
+	for k, v := range baggage {
+		found = found.WithBaggageItem(k, v)
+	}
+	return found, nil
+}
+
+// traceContextPropagator W3C Trace Context，traceparent: 00-{trace-id}-{parent-id}-{trace-flags}
+type traceContextPropagator struct{}
+
+// Inject ...
+func (traceContextPropagator) Inject(sc jaeger.SpanContext, carrier interface{}) error {
+	writer, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	flags := "00"
+	if sc.IsSampled() {
+		flags = "01"
+	}
+	writer.Set(traceParentHeader, fmt.Sprintf("00-%016x%016x-%016x-%s", sc.TraceID().High, sc.TraceID().Low, uint64(sc.SpanID()), flags))
+	return nil
+}
+
+// Extract ...
+func (traceContextPropagator) Extract(carrier interface{}) (jaeger.SpanContext, error) {
+	value, err := readHeader(carrier, traceParentHeader)
+	if err != nil {
+		return jaeger.SpanContext{}, err
+	}
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return jaeger.SpanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+	return newSpanContext(parts[1], parts[2], parts[3] == "01" || parts[3] == "03")
+}
+
+// b3SinglePropagator B3单头格式，b3: {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}
+type b3SinglePropagator struct{}
+
+// Inject ...
+func (b3SinglePropagator) Inject(sc jaeger.SpanContext, carrier interface{}) error {
+	writer, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+	// TraceId固定为16位或32位十六进制，不足的高位补0
+	traceID := fmt.Sprintf("%016x", sc.TraceID().Low)
+	if sc.TraceID().High != 0 {
+		traceID = fmt.Sprintf("%016x%016x", sc.TraceID().High, sc.TraceID().Low)
+	}
+	value := fmt.Sprintf("%s-%016x-%s", traceID, uint64(sc.SpanID()), sampled)
+	if sc.ParentID() != 0 {
+		value += fmt.Sprintf("-%016x", uint64(sc.ParentID()))
+	}
+	writer.Set(b3SingleHeader, value)
+	return nil
+}
+
+// Extract ...
+func (b3SinglePropagator) Extract(carrier interface{}) (jaeger.SpanContext, error) {
+	value, err := readHeader(carrier, b3SingleHeader)
+	if err != nil {
+		return jaeger.SpanContext{}, err
+	}
+	parts := strings.Split(value, "-")
+	if len(parts) < 2 {
+		return jaeger.SpanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+	sampled := len(parts) > 2 && (parts[2] == "1" || parts[2] == "d")
+	return newSpanContext(parts[0], parts[1], sampled)
+}
+
+// baggagePropagator W3C Baggage，baggage: k1=v1,k2=v2，只传递baggage，不包含span
+type baggagePropagator struct{}
+
+// Inject ...
+func (baggagePropagator) Inject(sc jaeger.SpanContext, carrier interface{}) error {
+	writer, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	members := make([]string, 0)
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		members = append(members, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		return true
+	})
+	if len(members) > 0 {
+		writer.Set(baggageHeader, strings.Join(members, ","))
+	}
+	return nil
+}
+
+// Extract ...
+func (baggagePropagator) Extract(carrier interface{}) (jaeger.SpanContext, error) {
+	value, err := readHeader(carrier, baggageHeader)
+	if err != nil {
+		return jaeger.SpanContext{}, err
+	}
+	baggage := make(map[string]string)
+	for _, member := range strings.Split(value, ",") {
+		// 忽略;之后的属性
+		kv := strings.SplitN(strings.SplitN(member, ";", 2)[0], "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		k, errK := url.QueryUnescape(strings.TrimSpace(kv[0]))
+		v, errV := url.QueryUnescape(strings.TrimSpace(kv[1]))
+		if errK != nil || errV != nil {
+			continue
+		}
+		baggage[k] = v
+	}
+	return jaeger.NewSpanContext(jaeger.TraceID{}, 0, 0, false, baggage), nil
+}
+
+// readHeader 大小写不敏感地读取header
+func readHeader(carrier interface{}, name string) (string, error) {
+	reader, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return "", opentracing.ErrInvalidCarrier
+	}
+	var value string
+	_ = reader.ForeachKey(func(key, val string) error {
+		if strings.ToLower(key) == name {
+			value = val
+		}
+		return nil
+	})
+	if value == "" {
+		return "", opentracing.ErrSpanContextNotFound
+	}
+	return value, nil
+}
+
+func newSpanContext(traceIDStr, spanIDStr string, sampled bool) (jaeger.SpanContext, error) {
+	traceID, err := jaeger.TraceIDFromString(traceIDStr)
+	if err != nil || !traceID.IsValid() {
+		return jaeger.SpanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+	spanID, err := jaeger.SpanIDFromString(spanIDStr)
+	if err != nil {
+		return jaeger.SpanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+	return jaeger.NewSpanContext(traceID, spanID, 0, sampled, nil), nil
+}
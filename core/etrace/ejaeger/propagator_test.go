@@ -0,0 +1,101 @@
+package ejaeger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber/jaeger-client-go"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+func newTestTracer(t *testing.T, propagators ...string) (opentracing.Tracer, *Config) {
+	config := DefaultConfig()
+	config.Sampler.Param = 1
+	config.Propagators = propagators
+	tracer := config.Build()
+	t.Cleanup(func() { _ = config.Stop() })
+	return tracer, config
+}
+
+func TestInjectAll(t *testing.T) {
+	tracer, _ := newTestTracer(t,
+		etrace.PropagatorTraceContext,
+		etrace.PropagatorBaggage,
+		etrace.PropagatorB3,
+		etrace.PropagatorB3Multi,
+		etrace.PropagatorJaeger,
+	)
+	span := tracer.StartSpan("test")
+	span.SetBaggageItem("user", "ego")
+	defer span.Finish()
+
+	hdr := make(http.Header)
+	assert.NoError(t, tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(hdr)))
+	sc := span.Context().(jaeger.SpanContext)
+	assert.Contains(t, hdr.Get("traceparent"), sc.TraceID().String())
+	assert.Equal(t, "user=ego", hdr.Get("baggage"))
+	assert.Contains(t, hdr.Get("b3"), sc.TraceID().String())
+	assert.Equal(t, sc.TraceID().String(), hdr.Get("X-B3-Traceid"))
+	assert.NotEmpty(t, hdr.Get(DefaultConfig().Headers.TraceContextHeaderName))
+
+	// 使用注入的header提取，得到同一个trace
+	extracted, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(hdr))
+	assert.NoError(t, err)
+	assert.Equal(t, sc.TraceID(), extracted.(jaeger.SpanContext).TraceID())
+	assert.Equal(t, "ego", baggageItem(extracted, "user"))
+}
+
+func TestExtractInOrder(t *testing.T) {
+	hdr := make(http.Header)
+	hdr.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	hdr.Set("b3", "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1")
+	hdr.Set("baggage", "tenant=a%20b")
+
+	tracer, _ := newTestTracer(t, etrace.PropagatorB3, etrace.PropagatorTraceContext, etrace.PropagatorBaggage)
+	sc, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(hdr))
+	assert.NoError(t, err)
+	assert.Equal(t, "80f198ee56343ba864fe8b2a57d3eff7", sc.(jaeger.SpanContext).TraceID().String())
+	assert.True(t, sc.(jaeger.SpanContext).IsSampled())
+	assert.Equal(t, "a b", baggageItem(sc, "tenant"))
+
+	tracer, _ = newTestTracer(t, etrace.PropagatorTraceContext, etrace.PropagatorB3)
+	sc, err = tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(hdr))
+	assert.NoError(t, err)
+	assert.Equal(t, "af7651916cd43dd8448eb211c80319c", sc.(jaeger.SpanContext).TraceID().String())
+
+	// 只配置jaeger时不识别其他格式
+	tracer, _ = newTestTracer(t, etrace.PropagatorJaeger)
+	_, err = tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(hdr))
+	assert.Equal(t, opentracing.ErrSpanContextNotFound, err)
+}
+
+func baggageItem(sc opentracing.SpanContext, key string) (value string) {
+	sc.ForeachBaggageItem(func(k, v string) bool {
+		if k == key {
+			value = v
+			return false
+		}
+		return true
+	})
+	return
+}
+
+func TestB3SingleInjectPadding(t *testing.T) {
+	hdr := make(http.Header)
+	carrier := opentracing.HTTPHeadersCarrier(hdr)
+	sc := jaeger.NewSpanContext(jaeger.TraceID{Low: 0xabc}, 0x1, 0x2, true, nil)
+	assert.NoError(t, b3SinglePropagator{}.Inject(sc, carrier))
+	assert.Equal(t, "0000000000000abc-0000000000000001-1-0000000000000002", hdr.Get("b3"))
+
+	sc = jaeger.NewSpanContext(jaeger.TraceID{High: 0x1, Low: 0xabc}, 0x1, 0, false, nil)
+	assert.NoError(t, b3SinglePropagator{}.Inject(sc, carrier))
+	assert.Equal(t, "00000000000000010000000000000abc-0000000000000001-0", hdr.Get("b3"))
+
+	// 补0后的TraceId可以被正确提取
+	extracted, err := b3SinglePropagator{}.Extract(carrier)
+	assert.NoError(t, err)
+	assert.Equal(t, sc.TraceID(), extracted.TraceID())
+}
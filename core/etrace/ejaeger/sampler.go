@@ -0,0 +1,67 @@
+package ejaeger
+
+import (
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/uber/jaeger-client-go"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// jaegerSampler 将etrace.Sampler转换为jaeger.SamplerV2
+// 未采样的根span在SampleOnError开启时不结束采样决策，之后打上error标签时再采样
+type jaegerSampler struct {
+	sampler *etrace.Sampler
+}
+
+var _ jaeger.SamplerV2 = (*jaegerSampler)(nil)
+
+// OnCreateSpan 只对进程内的根span做采样决策，子span跟随根span
+func (s *jaegerSampler) OnCreateSpan(span *jaeger.Span) jaeger.SamplingDecision {
+	if span.SpanContext().ParentID() == 0 {
+		return s.decide(span.OperationName(), span.SpanContext().TraceID().Low)
+	}
+	return jaeger.SamplingDecision{Sample: false, Retryable: s.sampler.SampleOnError()}
+}
+
+// OnSetOperationName 根span已经在创建时决策过，改名后不再重新决策，避免ratelimiting重复消耗配额，
+// 只保留出错时采样的机会
+func (s *jaegerSampler) OnSetOperationName(span *jaeger.Span, operationName string) jaeger.SamplingDecision {
+	return jaeger.SamplingDecision{Sample: false, Retryable: s.sampler.SampleOnError()}
+}
+
+// OnSetTag 出错时采样
+func (s *jaegerSampler) OnSetTag(span *jaeger.Span, key string, value interface{}) jaeger.SamplingDecision {
+	if !s.sampler.SampleOnError() {
+		return jaeger.SamplingDecision{Sample: false, Retryable: false}
+	}
+	if v, ok := value.(bool); ok && v && key == string(ext.Error) {
+		return jaeger.SamplingDecision{Sample: true, Retryable: false}
+	}
+	return jaeger.SamplingDecision{Sample: false, Retryable: true}
+}
+
+// OnFinishSpan ...
+func (s *jaegerSampler) OnFinishSpan(span *jaeger.Span) jaeger.SamplingDecision {
+	return jaeger.SamplingDecision{Sample: false, Retryable: s.sampler.SampleOnError()}
+}
+
+// Close ...
+func (s *jaegerSampler) Close() {}
+
+// IsSampled 兼容jaeger.Sampler
+func (s *jaegerSampler) IsSampled(id jaeger.TraceID, operation string) (bool, []jaeger.Tag) {
+	return s.sampler.IsSampled(operation, id.Low), nil
+}
+
+// Equal 兼容jaeger.Sampler
+func (s *jaegerSampler) Equal(other jaeger.Sampler) bool {
+	o, ok := other.(*jaegerSampler)
+	return ok && o.sampler == s.sampler
+}
+
+func (s *jaegerSampler) decide(operation string, traceID uint64) jaeger.SamplingDecision {
+	if s.sampler.IsSampled(operation, traceID) {
+		return jaeger.SamplingDecision{Sample: true, Retryable: false}
+	}
+	return jaeger.SamplingDecision{Sample: false, Retryable: s.sampler.SampleOnError()}
+}
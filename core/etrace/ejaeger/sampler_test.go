@@ -0,0 +1,65 @@
+package ejaeger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+	jconfig "github.com/uber/jaeger-client-go/config"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+func TestSampler(t *testing.T) {
+	samplerConfig := etrace.DefaultSamplerConfig()
+	samplerConfig.Type = etrace.SamplerConst
+	samplerConfig.Param = 0
+	samplerConfig.Operations = []etrace.OperationSamplerConfig{{Operation: "always", Type: etrace.SamplerConst, Param: 1}}
+	sampler, err := etrace.NewSampler(samplerConfig)
+	require.NoError(t, err)
+	etrace.SetGlobalSampler(sampler)
+	defer etrace.SetGlobalSampler(nil)
+
+	reporter := jaeger.NewInMemoryReporter()
+	config := DefaultConfig().WithSampler(sampler)
+	tracer := config.Build(jconfig.Reporter(reporter))
+	defer config.Stop()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+
+	tracer.StartSpan("dropped").Finish()
+	tracer.StartSpan("always").Finish()
+
+	// 出错时采样，之后结束的子span、根span都上报
+	root := tracer.StartSpan("error")
+	child := tracer.StartSpan("child", opentracing.ChildOf(root.Context()))
+	ext.Error.Set(child, true)
+	child.Finish()
+	root.Finish()
+
+	// 请求头强制采样
+	tracer.StartSpan("forced", etrace.HeaderExtractor(http.Header{"X-Ego-Force-Sample": []string{"1"}})).Finish()
+
+	// 根span改名后不重新决策
+	renamed := tracer.StartSpan("renamed")
+	renamed.SetOperationName("always")
+	renamed.Finish()
+
+	names := make([]string, 0)
+	for _, span := range reporter.GetSpans() {
+		names = append(names, span.(*jaeger.Span).OperationName())
+	}
+	assert.Equal(t, []string{"always", "child", "error", "forced"}, names)
+
+	// 更新配置后立即生效
+	samplerConfig = etrace.DefaultSamplerConfig()
+	samplerConfig.SampleOnError = false
+	require.NoError(t, sampler.Update(samplerConfig))
+	reporter.Reset()
+	tracer.StartSpan("sampled").Finish()
+	assert.Equal(t, 1, reporter.SpansSubmitted())
+}
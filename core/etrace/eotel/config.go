@@ -0,0 +1,172 @@
+package eotel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+)
+
+// Config ...
+type Config struct {
+	ServiceName  string            // 服务名，默认应用名
+	Protocol     string            // OTLP协议，可选[grpc|http]，默认grpc
+	Endpoint     string            // OTLP collector地址，为空时读取环境变量OTEL_EXPORTER_OTLP_ENDPOINT，默认grpc为localhost:4317，http为localhost:4318
+	URLPath      string            // [http]OTLP请求路径，默认/v1/traces
+	Insecure     bool              // 是否不使用TLS，默认true
+	Headers      map[string]string // OTLP请求头，例如鉴权信息
+	Timeout      time.Duration     // 导出超时，默认10秒
+	SampleRatio  float64           // 根span采样比例，子span跟随父span，默认1，设置了WithSampler时不生效
+	Propagators  []string          // 链路传递格式，按顺序组合，可选[tracecontext|baggage|b3|b3multi|jaeger]，默认[tracecontext,baggage]
+	PanicOnError bool              // 构造失败时是否panic，默认true
+	tags         []attribute.KeyValue
+	provider     *sdktrace.TracerProvider
+	sampler      *etrace.Sampler
+}
+
+// Load 加载配置key
+func Load(key string) *Config {
+	var config = DefaultConfig()
+	if err := econf.UnmarshalKey(key, config); err != nil {
+		elog.Panic("unmarshal key", elog.FieldErr(err))
+	}
+	return config
+}
+
+// DefaultConfig ...
+func DefaultConfig() *Config {
+	return &Config{
+		ServiceName: eapp.Name(),
+		Protocol:    protocolGRPC,
+		Insecure:    true,
+		Timeout:     10 * time.Second,
+		SampleRatio: 1,
+		Propagators: []string{etrace.PropagatorTraceContext, etrace.PropagatorBaggage},
+		tags: []attribute.KeyValue{
+			attribute.String("hostname", eapp.HostName()),
+		},
+		PanicOnError: true,
+	}
+}
+
+// WithTag 设置resource属性
+func (config *Config) WithTag(tags ...attribute.KeyValue) *Config {
+	if config.tags == nil {
+		config.tags = make([]attribute.KeyValue, 0)
+	}
+	config.tags = append(config.tags, tags...)
+	return config
+}
+
+// WithSampler 使用etrace.Sampler采样，配置变更时无需重建tracer，覆盖SampleRatio配置
+func (config *Config) WithSampler(sampler *etrace.Sampler) *Config {
+	config.sampler = sampler
+	return config
+}
+
+// Build 构造opentelemetry的TracerProvider，并返回桥接后的opentracing.Tracer，
+// 框架内的拦截器都通过opentracing接口使用tracer
+func (config *Config) Build() opentracing.Tracer {
+	propagator, err := newPropagator(config.Propagators)
+	if err != nil {
+		return config.buildFail("new otel propagator", err)
+	}
+	exporter, err := config.newExporter()
+	if err != nil {
+		return config.buildFail("new otel exporter", err)
+	}
+
+	attrs := append([]attribute.KeyValue{semconv.ServiceNameKey.String(config.ServiceName)}, config.tags...)
+	var (
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+		sampler   = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))
+	)
+	if config.sampler != nil {
+		processor = &errorSpanProcessor{SpanProcessor: processor, sampler: config.sampler}
+		sampler = &otelSampler{sampler: config.sampler}
+	}
+	config.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+		sdktrace.WithSampler(sampler),
+	)
+	bridge, wrapper := otbridge.NewTracerPair(config.provider.Tracer("github.com/gotomicro/ego"))
+	bridge.SetTextMapPropagator(propagator)
+	// 直接使用opentelemetry API的代码与框架共用同一个provider
+	otel.SetTracerProvider(wrapper)
+	otel.SetTextMapPropagator(propagator)
+	return bridge
+}
+
+func (config *Config) buildFail(msg string, err error) opentracing.Tracer {
+	if config.PanicOnError {
+		elog.Panic(msg, elog.FieldComponent("otel"), elog.FieldErr(err))
+	}
+	elog.Error(msg, elog.FieldComponent("otel"), elog.FieldErr(err))
+	return opentracing.NoopTracer{}
+}
+
+func (config *Config) newExporter() (*otlptrace.Exporter, error) {
+	ctx := context.Background()
+	switch config.Protocol {
+	case protocolHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithTimeout(config.Timeout),
+			otlptracehttp.WithHeaders(config.Headers),
+		}
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.URLPath != "" {
+			opts = append(opts, otlptracehttp.WithURLPath(config.URLPath))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case protocolGRPC, "":
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithTimeout(config.Timeout),
+			otlptracegrpc.WithHeaders(config.Headers),
+		}
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported otlp protocol: %s", config.Protocol)
+	}
+}
+
+// Stop 停止，导出剩余的span
+func (config *Config) Stop() error {
+	if config.provider == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	return config.provider.Shutdown(ctx)
+}
@@ -0,0 +1,48 @@
+package eotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+func TestBuildHTTPExporter(t *testing.T) {
+	var received int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			atomic.AddInt32(&received, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig()
+	config.Protocol = protocolHTTP
+	config.Endpoint = strings.TrimPrefix(ts.URL, "http://")
+	etrace.SetGlobalTracer(config.Build())
+
+	span, ctx := etrace.StartSpanFromContext(context.Background(), "parent")
+	traceID := etrace.ExtractTraceID(ctx)
+	assert.Len(t, traceID, 32)
+
+	// 通过gRPC metadata传递后，服务端span与客户端span属于同一个trace
+	ctx = etrace.MetadataInjector(ctx, metadata.New(nil))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Len(t, md.Get("traceparent"), 1)
+	serverCtx := metadata.NewIncomingContext(context.Background(), md)
+	serverSpan, serverCtx := etrace.StartSpanFromContext(serverCtx, "server", etrace.FromIncomingContext(serverCtx))
+	assert.Equal(t, traceID, etrace.ExtractTraceID(serverCtx))
+	serverSpan.Finish()
+	span.Finish()
+
+	assert.NoError(t, config.Stop())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&received))
+}
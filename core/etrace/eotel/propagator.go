@@ -0,0 +1,78 @@
+package eotel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// newPropagator 按配置顺序组合propagator
+func newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	propagators := make(orderedPropagator, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case etrace.PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case etrace.PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case etrace.PropagatorB3:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case etrace.PropagatorB3Multi:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		case etrace.PropagatorJaeger:
+			propagators = append(propagators, jaeger.Jaeger{})
+		default:
+			return nil, fmt.Errorf("unsupported propagator: %s", name)
+		}
+	}
+	return propagators, nil
+}
+
+// orderedPropagator 注入时写入全部格式，提取时保留第一个提取出的span，
+// 与opentelemetry默认的composite不同，后面的格式不会覆盖前面的结果，但仍然会提取baggage
+type orderedPropagator []propagation.TextMapPropagator
+
+// Inject ...
+func (p orderedPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	for _, i := range p {
+		i.Inject(ctx, carrier)
+	}
+}
+
+// Extract ...
+func (p orderedPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	var found trace.SpanContext
+	for _, i := range p {
+		ctx = i.Extract(ctx, carrier)
+		if found.IsValid() {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, found)
+			continue
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			found = sc
+		}
+	}
+	return ctx
+}
+
+// Fields ...
+func (p orderedPropagator) Fields() []string {
+	unique := make(map[string]struct{})
+	fields := make([]string, 0)
+	for _, i := range p {
+		for _, k := range i.Fields() {
+			if _, ok := unique[k]; ok {
+				continue
+			}
+			unique[k] = struct{}{}
+			fields = append(fields, k)
+		}
+	}
+	return fields
+}
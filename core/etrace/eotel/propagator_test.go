@@ -0,0 +1,40 @@
+package eotel
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+func TestPropagatorOrder(t *testing.T) {
+	hdr := make(http.Header)
+	hdr.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	hdr.Set("b3", "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1")
+	hdr.Set("baggage", "tenant=a")
+
+	p, err := newPropagator([]string{etrace.PropagatorB3, etrace.PropagatorTraceContext, etrace.PropagatorBaggage})
+	assert.NoError(t, err)
+	ctx := p.Extract(context.Background(), propagation.HeaderCarrier(hdr))
+	assert.Equal(t, "80f198ee56343ba864fe8b2a57d3eff7", trace.SpanContextFromContext(ctx).TraceID().String())
+	assert.Equal(t, "a", baggage.FromContext(ctx).Member("tenant").Value())
+
+	p, err = newPropagator([]string{etrace.PropagatorTraceContext, etrace.PropagatorB3})
+	assert.NoError(t, err)
+	ctx = p.Extract(context.Background(), propagation.HeaderCarrier(hdr))
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", trace.SpanContextFromContext(ctx).TraceID().String())
+
+	out := make(http.Header)
+	p.Inject(ctx, propagation.HeaderCarrier(out))
+	assert.Contains(t, out.Get("traceparent"), "0af7651916cd43dd8448eb211c80319c")
+	assert.Contains(t, out.Get("b3"), "0af7651916cd43dd8448eb211c80319c")
+
+	_, err = newPropagator([]string{"unknown"})
+	assert.Error(t, err)
+}
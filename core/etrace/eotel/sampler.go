@@ -0,0 +1,80 @@
+package eotel
+
+import (
+	"context"
+	"encoding/binary"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// otelSampler 将etrace.Sampler转换为sdktrace.Sampler
+// 未采样的span在SampleOnError开启时仍然记录(RecordOnly)，结束时出错再由errorSpanProcessor上报
+type otelSampler struct {
+	sampler *etrace.Sampler
+}
+
+// ShouldSample 强制采样优先，其次跟随父span，根span按策略采样
+func (s *otelSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	result := sdktrace.SamplingResult{Tracestate: parent.TraceState()}
+	tags := make(map[string]interface{}, len(p.Attributes))
+	for _, attr := range p.Attributes {
+		tags[string(attr.Key)] = attr.Value.Emit()
+	}
+	switch {
+	case etrace.IsForced(tags):
+		result.Decision = sdktrace.RecordAndSample
+	case parent.IsValid() && parent.IsSampled():
+		result.Decision = sdktrace.RecordAndSample
+	// opentracing bridge传入的父span都被视为远程span，无法区分进程内外，未采样的父span统一按SampleOnError处理
+	case !parent.IsValid() && s.sampler.IsSampled(p.Name, binary.BigEndian.Uint64(p.TraceID[8:])):
+		result.Decision = sdktrace.RecordAndSample
+	case s.sampler.SampleOnError():
+		result.Decision = sdktrace.RecordOnly
+	default:
+		result.Decision = sdktrace.Drop
+	}
+	return result
+}
+
+// Description ...
+func (s *otelSampler) Description() string {
+	return "EgoSampler"
+}
+
+// errorSpanProcessor 将出错的未采样span当作采样span交给下一个processor
+type errorSpanProcessor struct {
+	sdktrace.SpanProcessor
+	sampler *etrace.Sampler
+}
+
+// OnEnd ...
+func (p *errorSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+	if p.sampler.SampleOnError() && s.Status().Code == codes.Error {
+		p.SpanProcessor.OnEnd(sampledSpan{ReadOnlySpan: s})
+	}
+}
+
+// OnStart ...
+func (p *errorSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.SpanProcessor.OnStart(parent, s)
+}
+
+// sampledSpan 将span标记为已采样，exporter只导出已采样的span
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+// SpanContext ...
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
@@ -0,0 +1,64 @@
+package eotel
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+func TestSampler(t *testing.T) {
+	samplerConfig := etrace.DefaultSamplerConfig()
+	samplerConfig.Type = etrace.SamplerConst
+	samplerConfig.Param = 0
+	samplerConfig.Operations = []etrace.OperationSamplerConfig{{Operation: "always", Type: etrace.SamplerConst, Param: 1}}
+	sampler, err := etrace.NewSampler(samplerConfig)
+	require.NoError(t, err)
+	etrace.SetGlobalSampler(sampler)
+	defer etrace.SetGlobalSampler(nil)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(&errorSpanProcessor{SpanProcessor: sdktrace.NewSimpleSpanProcessor(exporter), sampler: sampler}),
+		sdktrace.WithSampler(&otelSampler{sampler: sampler}),
+	)
+	tracer, _ := otbridge.NewTracerPair(provider.Tracer("test"))
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+
+	tracer.StartSpan("dropped").Finish()
+	always := tracer.StartSpan("always")
+	tracer.StartSpan("always.child", opentracing.ChildOf(always.Context())).Finish()
+	always.Finish()
+
+	// 未采样的span出错时单独上报
+	root := tracer.StartSpan("error")
+	child := tracer.StartSpan("child", opentracing.ChildOf(root.Context()))
+	ext.Error.Set(child, true)
+	child.Finish()
+	root.Finish()
+
+	// 请求头强制采样
+	tracer.StartSpan("forced", etrace.HeaderExtractor(http.Header{"X-Ego-Force-Sample": []string{"1"}})).Finish()
+
+	names := make([]string, 0)
+	for _, span := range exporter.GetSpans() {
+		names = append(names, span.Name)
+	}
+	assert.Equal(t, []string{"always.child", "always", "child", "forced"}, names)
+
+	samplerConfig = etrace.DefaultSamplerConfig()
+	samplerConfig.SampleOnError = false
+	require.NoError(t, sampler.Update(samplerConfig))
+	exporter.Reset()
+	tracer.StartSpan("sampled").Finish()
+	assert.Len(t, exporter.GetSpans(), 1)
+}
@@ -0,0 +1,144 @@
+// Package etracetest 提供测试使用的内存tracer，记录结束的span，可以直接断言egin、egrpc、ecron等组件产生的链路
+package etracetest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// Tracer 基于mocktracer的内存tracer，在其之上提供按名称、父子关系查找span的方法，
+// span的SpanContext实现了TraceID() string，etrace.ExtractTraceID可以直接读取
+type Tracer struct {
+	*mocktracer.MockTracer
+}
+
+// New 构造内存tracer
+func New() *Tracer {
+	return &Tracer{MockTracer: mocktracer.New()}
+}
+
+// SpanContext 包装mocktracer的SpanContext，通过TraceID() string供etrace.ExtractTraceID读取
+type SpanContext struct {
+	mocktracer.MockSpanContext
+}
+
+// TraceID 返回十进制的trace id
+func (sc SpanContext) TraceID() string {
+	return strconv.Itoa(sc.MockSpanContext.TraceID)
+}
+
+// span Context()返回包装后的SpanContext
+type span struct {
+	*mocktracer.MockSpan
+}
+
+// Context ...
+func (s span) Context() opentracing.SpanContext {
+	return SpanContext{MockSpanContext: s.MockSpan.Context().(mocktracer.MockSpanContext)}
+}
+
+// startSpanOptions 将解开包装后的选项传给mocktracer
+type startSpanOptions opentracing.StartSpanOptions
+
+// Apply ...
+func (o startSpanOptions) Apply(options *opentracing.StartSpanOptions) {
+	*options = opentracing.StartSpanOptions(o)
+}
+
+// StartSpan 创建span，父span的SpanContext解开包装后交给mocktracer
+func (t *Tracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	options := opentracing.StartSpanOptions{}
+	for _, opt := range opts {
+		opt.Apply(&options)
+	}
+	references := make([]opentracing.SpanReference, 0, len(options.References))
+	for _, ref := range options.References {
+		ref.ReferencedContext = unwrap(ref.ReferencedContext)
+		references = append(references, ref)
+	}
+	options.References = references
+	return span{MockSpan: t.MockTracer.StartSpan(operationName, startSpanOptions(options)).(*mocktracer.MockSpan)}
+}
+
+// Inject ...
+func (t *Tracer) Inject(sc opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	return t.MockTracer.Inject(unwrap(sc), format, carrier)
+}
+
+// Extract ...
+func (t *Tracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	sc, err := t.MockTracer.Extract(format, carrier)
+	if err != nil {
+		return nil, err
+	}
+	return SpanContext{MockSpanContext: sc.(mocktracer.MockSpanContext)}, nil
+}
+
+func unwrap(sc opentracing.SpanContext) opentracing.SpanContext {
+	if wrapped, ok := sc.(SpanContext); ok {
+		return wrapped.MockSpanContext
+	}
+	return sc
+}
+
+// Swap 在测试期间将全局tracer替换为内存tracer，测试结束后还原为之前的全局tracer。
+// 之前没有设置全局tracer时还原为NoopTracer，etrace.IsGlobalTracerRegistered随之返回false
+func Swap(t testing.TB) *Tracer {
+	t.Helper()
+	tracer := New()
+	previous := opentracing.GlobalTracer()
+	etrace.SetGlobalTracer(tracer)
+	t.Cleanup(func() {
+		opentracing.SetGlobalTracer(previous)
+	})
+	return tracer
+}
+
+// FindSpans 返回所有该operation name的已结束span
+func (t *Tracer) FindSpans(operationName string) []*mocktracer.MockSpan {
+	spans := make([]*mocktracer.MockSpan, 0)
+	for _, span := range t.FinishedSpans() {
+		if span.OperationName == operationName {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// FindSpan 返回第一个该operation name的已结束span，不存在返回nil
+func (t *Tracer) FindSpan(operationName string) *mocktracer.MockSpan {
+	if spans := t.FindSpans(operationName); len(spans) > 0 {
+		return spans[0]
+	}
+	return nil
+}
+
+// Children 返回parent的所有已结束子span
+func (t *Tracer) Children(parent *mocktracer.MockSpan) []*mocktracer.MockSpan {
+	spans := make([]*mocktracer.MockSpan, 0)
+	for _, span := range t.FinishedSpans() {
+		if span.ParentID == parent.SpanContext.SpanID && span.SpanContext.TraceID == parent.SpanContext.TraceID {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// AssertSpan 断言存在该operation name的已结束span，并返回第一个
+func (t *Tracer) AssertSpan(tb testing.TB, operationName string) *mocktracer.MockSpan {
+	tb.Helper()
+	span := t.FindSpan(operationName)
+	if span == nil {
+		names := make([]string, 0)
+		for _, s := range t.FinishedSpans() {
+			names = append(names, s.OperationName)
+		}
+		tb.Errorf("span %q not found, finished spans: %v", operationName, names)
+	}
+	return span
+}
@@ -0,0 +1,67 @@
+package etracetest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+func TestTracer(t *testing.T) {
+	tracer := Swap(t)
+
+	root, ctx := etrace.StartSpanFromContext(context.Background(), "root", etrace.TagComponent("test"))
+	root.SetBaggageItem("uid", "100")
+	assert.Equal(t, strconv.Itoa(root.Context().(SpanContext).MockSpanContext.TraceID), etrace.ExtractTraceID(ctx))
+
+	// 跨进程传递
+	header := http.Header{}
+	etrace.HeaderInjector(ctx, header)
+	server, _ := etrace.StartSpanFromContext(context.Background(), "server", etrace.HeaderExtractor(header))
+	assert.Equal(t, "100", server.BaggageItem("uid"))
+	server.LogFields(etrace.String("event", "error"))
+	server.Finish()
+
+	md := metadata.MD{}
+	etrace.MetadataInjector(ctx, md)
+	grpcServer, _ := etrace.StartSpanFromContext(context.Background(), "grpc", etrace.MetadataExtractor(md))
+	grpcServer.Finish()
+	root.Finish()
+
+	assert.Len(t, tracer.FinishedSpans(), 3)
+	rootSpan := tracer.AssertSpan(t, "root")
+	require.NotNil(t, rootSpan)
+	assert.Zero(t, rootSpan.ParentID)
+	assert.Equal(t, "test", rootSpan.Tag("component"))
+
+	children := tracer.Children(rootSpan)
+	require.Len(t, children, 2)
+	assert.Equal(t, "server", children[0].OperationName)
+	assert.Equal(t, "grpc", children[1].OperationName)
+	assert.Equal(t, "error", tracer.FindSpan("server").Logs()[0].Fields[0].ValueString)
+	assert.Nil(t, tracer.FindSpan("unknown"))
+
+	tracer.Reset()
+	assert.Empty(t, tracer.FinishedSpans())
+}
+
+func TestSwapRestore(t *testing.T) {
+	previous := opentracing.GlobalTracer()
+	defer opentracing.SetGlobalTracer(previous)
+	opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+
+	t.Run("swap", func(t *testing.T) {
+		Swap(t)
+		assert.True(t, etrace.IsGlobalTracerRegistered())
+	})
+	// 还原为NoopTracer后视为未设置全局tracer
+	assert.False(t, etrace.IsGlobalTracerRegistered())
+	assert.Equal(t, opentracing.NoopTracer{}, opentracing.GlobalTracer())
+}
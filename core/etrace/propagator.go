@@ -0,0 +1,16 @@
+package etrace
+
+// 跨进程传递链路信息的格式，在trace.jaeger.propagators、trace.otel.propagators中按顺序组合使用，
+// 注入时写入全部格式，提取时使用第一个能提取出链路的格式
+const (
+	// PropagatorTraceContext W3C Trace Context，traceparent、tracestate头
+	PropagatorTraceContext = "tracecontext"
+	// PropagatorBaggage W3C Baggage，baggage头
+	PropagatorBaggage = "baggage"
+	// PropagatorB3 B3单头格式，b3头
+	PropagatorB3 = "b3"
+	// PropagatorB3Multi B3多头格式，X-B3-*头
+	PropagatorB3Multi = "b3multi"
+	// PropagatorJaeger Jaeger格式，默认为uber-trace-id头，ejaeger中使用Headers配置的头
+	PropagatorJaeger = "jaeger"
+)
@@ -0,0 +1,281 @@
+package etrace
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// 采样策略
+const (
+	// SamplerConst 全部采样或全部不采样
+	SamplerConst = "const"
+	// SamplerProbabilistic 按trace id概率采样，同一个trace的采样结果一致
+	SamplerProbabilistic = "probabilistic"
+	// SamplerRateLimiting 限制每秒采样的trace数
+	SamplerRateLimiting = "ratelimiting"
+)
+
+// maxRandomNumber 与jaeger一致，只使用trace id的低63位做概率采样
+const maxRandomNumber = ^(uint64(1) << 63)
+
+// SamplerConfig 采样配置，配置在trace.sampler下，修改配置后无需重启即可生效
+type SamplerConfig struct {
+	Type              string                   // 默认采样策略，可选[const|probabilistic|ratelimiting]，默认probabilistic
+	Param             float64                  // const: 1全部采样，0不采样；probabilistic: 采样比例[0,1]；ratelimiting: 每秒最多采样的trace数，默认1
+	Operations        []OperationSamplerConfig // 按operation name配置的采样策略，优先于默认策略
+	SampleOnError     bool                     // 未被采样的span出错时仍然上报，默认true
+	ForceSampleHeader string                   // 请求带上该header并且值不为空、0、false时强制采样，默认X-Ego-Force-Sample
+}
+
+// OperationSamplerConfig 单个operation的采样配置
+type OperationSamplerConfig struct {
+	Operation string  // operation name，例如 GET./hello、/helloworld.Greeter/SayHello
+	Type      string  // 可选[const|probabilistic|ratelimiting]
+	Param     float64 // 与SamplerConfig.Param含义相同
+}
+
+// DefaultSamplerConfig ...
+func DefaultSamplerConfig() *SamplerConfig {
+	return &SamplerConfig{
+		Type:              SamplerProbabilistic,
+		Param:             1,
+		SampleOnError:     true,
+		ForceSampleHeader: "X-Ego-Force-Sample",
+	}
+}
+
+// Sampler 与tracer实现无关的采样器，由ejaeger、eotel转换为各自的采样接口
+type Sampler struct {
+	state atomic.Value // *samplerState
+}
+
+type samplerState struct {
+	config     *SamplerConfig
+	strategy   strategy
+	operations map[string]strategy
+}
+
+// globalSampler 全局采样器，HeaderExtractor等方法根据它的配置识别强制采样header
+var globalSampler atomic.Value // *Sampler
+
+// SetGlobalSampler 设置全局采样器，需要与全局tracer使用的采样器一致
+func SetGlobalSampler(sampler *Sampler) {
+	globalSampler.Store(sampler)
+}
+
+// LoadSampler 加载配置key，并在配置变更时更新采样策略
+func LoadSampler(key string) *Sampler {
+	config := DefaultSamplerConfig()
+	if err := econf.UnmarshalKey(key, config); err != nil {
+		elog.Panic("unmarshal key", elog.FieldComponent("trace"), elog.FieldErr(err), elog.FieldKey(key))
+	}
+	sampler, err := NewSampler(config)
+	if err != nil {
+		elog.Panic("new sampler", elog.FieldComponent("trace"), elog.FieldErr(err), elog.FieldKey(key))
+	}
+	econf.OnChange(func(c *econf.Configuration) {
+		sampler.reload(c, key)
+	})
+	return sampler
+}
+
+// NewSampler ...
+func NewSampler(config *SamplerConfig) (*Sampler, error) {
+	sampler := &Sampler{}
+	if err := sampler.Update(config); err != nil {
+		return nil, err
+	}
+	return sampler, nil
+}
+
+// reload 重新读取配置，配置错误时保留原有的采样策略
+func (s *Sampler) reload(c *econf.Configuration, key string) {
+	config := DefaultSamplerConfig()
+	if err := c.UnmarshalKey(key, config); err != nil {
+		elog.Error("unmarshal key", elog.FieldComponent("trace"), elog.FieldErr(err), elog.FieldKey(key))
+		return
+	}
+	if err := s.Update(config); err != nil {
+		elog.Error("update sampler", elog.FieldComponent("trace"), elog.FieldErr(err), elog.FieldKey(key))
+		return
+	}
+	elog.Info("update sampler", elog.FieldComponent("trace"), elog.FieldKey(key), elog.FieldValueAny(config))
+}
+
+// Update 更新采样策略，正在进行中的trace不受影响
+func (s *Sampler) Update(config *SamplerConfig) error {
+	st, err := newStrategy(config.Type, config.Param)
+	if err != nil {
+		return err
+	}
+	operations := make(map[string]strategy, len(config.Operations))
+	for _, op := range config.Operations {
+		if operations[op.Operation], err = newStrategy(op.Type, op.Param); err != nil {
+			return fmt.Errorf("operation %s: %w", op.Operation, err)
+		}
+	}
+	if prev, ok := s.state.Load().(*samplerState); ok {
+		st = keepStrategy(prev.strategy, st)
+		for name, op := range operations {
+			operations[name] = keepStrategy(prev.operations[name], op)
+		}
+	}
+	s.state.Store(&samplerState{config: config, strategy: st, operations: operations})
+	return nil
+}
+
+// IsSampled 根span是否采样，traceID取trace id的低64位
+func (s *Sampler) IsSampled(operation string, traceID uint64) bool {
+	st := s.state.Load().(*samplerState)
+	if op, ok := st.operations[operation]; ok {
+		return op.sample(traceID)
+	}
+	return st.strategy.sample(traceID)
+}
+
+// SampleOnError 未被采样的span出错时是否仍然上报
+func (s *Sampler) SampleOnError() bool {
+	return s.state.Load().(*samplerState).config.SampleOnError
+}
+
+// ForceSampleHeader 强制采样header
+func (s *Sampler) ForceSampleHeader() string {
+	return s.state.Load().(*samplerState).config.ForceSampleHeader
+}
+
+// IsForced tag中是否带有强制采样标记
+func IsForced(tags map[string]interface{}) bool {
+	v, ok := tags[string(ext.SamplingPriority)]
+	if !ok {
+		return false
+	}
+	switch val := v.(type) {
+	case uint16:
+		return val > 0
+	case int64:
+		return val > 0
+	case string:
+		return val != "" && val != "0"
+	}
+	return false
+}
+
+// forceSampleOption 请求头中带有全局采样器的强制采样header时，给span打上sampling.priority=1
+func forceSampleOption(hdr map[string][]string) opentracing.StartSpanOption {
+	sampler, _ := globalSampler.Load().(*Sampler)
+	if sampler == nil {
+		return NullStartSpanOption{}
+	}
+	name := sampler.ForceSampleHeader()
+	if name == "" {
+		return NullStartSpanOption{}
+	}
+	for k, vals := range hdr {
+		if !strings.EqualFold(k, name) || len(vals) == 0 {
+			continue
+		}
+		switch strings.ToLower(vals[0]) {
+		case "", "0", "false":
+			return NullStartSpanOption{}
+		}
+		return opentracing.Tag{Key: string(ext.SamplingPriority), Value: uint16(1)}
+	}
+	return NullStartSpanOption{}
+}
+
+type strategy interface {
+	sample(traceID uint64) bool
+}
+
+func newStrategy(typ string, param float64) (strategy, error) {
+	switch typ {
+	case SamplerConst:
+		return constStrategy(param != 0), nil
+	case SamplerProbabilistic, "":
+		if param < 0 || param > 1 {
+			return nil, fmt.Errorf("probabilistic sampler param must be in [0,1], got %v", param)
+		}
+		return probabilisticStrategy(uint64(float64(maxRandomNumber) * param)), nil
+	case SamplerRateLimiting:
+		if param < 0 {
+			return nil, fmt.Errorf("ratelimiting sampler param must not be negative, got %v", param)
+		}
+		return newRateLimitingStrategy(param), nil
+	default:
+		return nil, fmt.Errorf("unsupported sampler type: %s", typ)
+	}
+}
+
+// keepStrategy 限流策略的参数没有变化时沿用原来的令牌桶，避免每次配置变更都重置限流
+func keepStrategy(prev, next strategy) strategy {
+	p, ok := prev.(*rateLimitingStrategy)
+	if !ok {
+		return next
+	}
+	if n, ok := next.(*rateLimitingStrategy); ok && n.creditsPerSecond == p.creditsPerSecond {
+		return p
+	}
+	return next
+}
+
+type constStrategy bool
+
+func (c constStrategy) sample(uint64) bool {
+	return bool(c)
+}
+
+// probabilisticStrategy trace id低63位小于该值时采样
+type probabilisticStrategy uint64
+
+func (p probabilisticStrategy) sample(traceID uint64) bool {
+	return traceID&maxRandomNumber < uint64(p)
+}
+
+// rateLimitingStrategy 令牌桶，最多积累1秒的令牌
+type rateLimitingStrategy struct {
+	mu               sync.Mutex
+	creditsPerSecond float64
+	balance          float64
+	maxBalance       float64
+	lastTick         time.Time
+	timeNow          func() time.Time
+}
+
+func newRateLimitingStrategy(creditsPerSecond float64) *rateLimitingStrategy {
+	maxBalance := creditsPerSecond
+	if maxBalance < 1 {
+		maxBalance = 1
+	}
+	return &rateLimitingStrategy{
+		creditsPerSecond: creditsPerSecond,
+		balance:          maxBalance,
+		maxBalance:       maxBalance,
+		lastTick:         time.Now(),
+		timeNow:          time.Now,
+	}
+}
+
+func (r *rateLimitingStrategy) sample(uint64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.timeNow()
+	r.balance += now.Sub(r.lastTick).Seconds() * r.creditsPerSecond
+	r.lastTick = now
+	if r.balance > r.maxBalance {
+		r.balance = r.maxBalance
+	}
+	if r.balance >= 1 {
+		r.balance--
+		return true
+	}
+	return false
+}
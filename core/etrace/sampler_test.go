@@ -0,0 +1,134 @@
+package etrace
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+func TestSamplerStrategy(t *testing.T) {
+	sampler, err := NewSampler(&SamplerConfig{
+		Type:  SamplerProbabilistic,
+		Param: 0.5,
+		Operations: []OperationSamplerConfig{
+			{Operation: "GET./always", Type: SamplerConst, Param: 1},
+			{Operation: "GET./never", Type: SamplerConst, Param: 0},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, sampler.IsSampled("GET./hello", 1))
+	assert.False(t, sampler.IsSampled("GET./hello", maxRandomNumber))
+	// 只使用低63位
+	assert.True(t, sampler.IsSampled("GET./hello", 1<<63|1))
+	assert.True(t, sampler.IsSampled("GET./always", maxRandomNumber))
+	assert.False(t, sampler.IsSampled("GET./never", 1))
+
+	_, err = NewSampler(&SamplerConfig{Type: "unknown"})
+	assert.Error(t, err)
+	_, err = NewSampler(&SamplerConfig{Type: SamplerProbabilistic, Param: 2})
+	assert.Error(t, err)
+	_, err = NewSampler(&SamplerConfig{Type: SamplerProbabilistic, Operations: []OperationSamplerConfig{{Operation: "a", Type: "unknown"}}})
+	assert.Error(t, err)
+}
+
+func TestRateLimitingStrategy(t *testing.T) {
+	now := time.Now()
+	r := newRateLimitingStrategy(2)
+	r.lastTick = now
+	r.timeNow = func() time.Time { return now }
+	assert.True(t, r.sample(0))
+	assert.True(t, r.sample(0))
+	assert.False(t, r.sample(0))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, r.sample(0))
+	assert.False(t, r.sample(0))
+
+	// 最多积累1秒的令牌
+	now = now.Add(10 * time.Second)
+	assert.True(t, r.sample(0))
+	assert.True(t, r.sample(0))
+	assert.False(t, r.sample(0))
+}
+
+func TestSamplerReload(t *testing.T) {
+	sampler, err := NewSampler(DefaultSamplerConfig())
+	require.NoError(t, err)
+	assert.True(t, sampler.IsSampled("GET./hello", maxRandomNumber-1))
+	assert.True(t, sampler.SampleOnError())
+
+	c := econf.New()
+	require.NoError(t, c.LoadFromReader(bytes.NewBufferString(`
+[trace.sampler]
+type = "const"
+param = 0
+sampleOnError = false
+`), toml.Unmarshal))
+	sampler.reload(c, "trace.sampler")
+	assert.False(t, sampler.IsSampled("GET./hello", 1))
+	assert.False(t, sampler.SampleOnError())
+
+	// 配置错误时保留原有策略
+	require.NoError(t, c.LoadFromReader(bytes.NewBufferString(`
+[trace.sampler]
+type = "unknown"
+`), toml.Unmarshal))
+	sampler.reload(c, "trace.sampler")
+	assert.False(t, sampler.IsSampled("GET./hello", 1))
+}
+
+func TestForceSampleHeader(t *testing.T) {
+	sampler, err := NewSampler(DefaultSamplerConfig())
+	require.NoError(t, err)
+	SetGlobalSampler(sampler)
+	defer SetGlobalSampler(nil)
+	// 其他采样器的配置不影响全局采样器
+	other := DefaultSamplerConfig()
+	other.ForceSampleHeader = "X-Other"
+	_, err = NewSampler(other)
+	require.NoError(t, err)
+
+	forced := func(hdr http.Header) bool {
+		options := opentracing.StartSpanOptions{}
+		HeaderExtractor(hdr).Apply(&options)
+		return IsForced(options.Tags)
+	}
+	assert.False(t, forced(http.Header{}))
+	assert.True(t, forced(http.Header{"X-Ego-Force-Sample": []string{"1"}}))
+	assert.False(t, forced(http.Header{"X-Ego-Force-Sample": []string{"false"}}))
+
+	options := opentracing.StartSpanOptions{}
+	MetadataExtractor(map[string][]string{"x-ego-force-sample": {"true"}}).Apply(&options)
+	assert.True(t, IsForced(options.Tags))
+
+	// 没有设置全局采样器时不识别强制采样header
+	SetGlobalSampler(nil)
+	assert.False(t, forced(http.Header{"X-Ego-Force-Sample": []string{"1"}}))
+}
+
+func TestSamplerKeepRateLimiter(t *testing.T) {
+	config := &SamplerConfig{Type: SamplerRateLimiting, Param: 1, Operations: []OperationSamplerConfig{{Operation: "a", Type: SamplerRateLimiting, Param: 1}}}
+	sampler, err := NewSampler(config)
+	require.NoError(t, err)
+	assert.True(t, sampler.IsSampled("GET./hello", 0))
+	assert.False(t, sampler.IsSampled("GET./hello", 0))
+	assert.True(t, sampler.IsSampled("a", 0))
+	assert.False(t, sampler.IsSampled("a", 0))
+
+	// 限流参数不变时沿用原来的令牌桶，重新加载配置不会重置限流
+	require.NoError(t, sampler.Update(config))
+	assert.False(t, sampler.IsSampled("GET./hello", 0))
+	assert.False(t, sampler.IsSampled("a", 0))
+
+	// 参数变化时使用新的令牌桶
+	require.NoError(t, sampler.Update(&SamplerConfig{Type: SamplerRateLimiting, Param: 2}))
+	assert.True(t, sampler.IsSampled("GET./hello", 0))
+}
@@ -3,10 +3,10 @@ package etrace
 import (
 	"context"
 
-	"github.com/uber/jaeger-client-go"
-
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
+	"github.com/uber/jaeger-client-go"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/gotomicro/ego/core/elog"
 )
@@ -16,12 +16,21 @@ var (
 	String = log.String
 )
 
-// SetGlobalTracer ...
+// SetGlobalTracer 设置全局tracer，可以是ejaeger、eotel构造的tracer，或者其他opentracing实现
 func SetGlobalTracer(tracer opentracing.Tracer) {
 	elog.EgoLogger.Info("set global tracer", elog.FieldComponent("trace"))
 	opentracing.SetGlobalTracer(tracer)
 }
 
+// IsGlobalTracerRegistered 是否设置了全局tracer，全局tracer被还原为NoopTracer时视为未设置
+func IsGlobalTracerRegistered() bool {
+	if !opentracing.IsGlobalTracerRegistered() {
+		return false
+	}
+	_, noop := opentracing.GlobalTracer().(opentracing.NoopTracer)
+	return !noop
+}
+
 // StartSpanFromContext ...
 func StartSpanFromContext(ctx context.Context, op string, opts ...opentracing.StartSpanOption) (opentracing.Span, context.Context) {
 	return opentracing.StartSpanFromContext(ctx, op, opts...)
@@ -33,14 +42,26 @@ func SpanFromContext(ctx context.Context) opentracing.Span {
 }
 
 // ExtractTraceID HTTP使用request.Context，不要使用错了
+// 不依赖具体的tracer实现，无法识别时返回空字符串
 func ExtractTraceID(ctx context.Context) string {
-	if !opentracing.IsGlobalTracerRegistered() {
+	if !IsGlobalTracerRegistered() {
 		return ""
 	}
 
+	// opentelemetry的bridge会把span同时放入context
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+
 	span := opentracing.SpanFromContext(ctx)
 	if span == nil {
 		return ""
 	}
-	return span.(*jaeger.Span).Context().(jaeger.SpanContext).TraceID().String()
+	switch sc := span.Context().(type) {
+	case jaeger.SpanContext:
+		return sc.TraceID().String()
+	case interface{ TraceID() string }:
+		return sc.TraceID()
+	}
+	return ""
 }
@@ -82,8 +82,12 @@ func New(options ...Option) *Ego {
 
 	// 设置运行前清理函数
 	// 如果注册中心存在设置
+	// 通过闭包关闭，使用Registry替换的注册中心同样会被关闭
 	if e.registerer != nil {
-		options = append(options, WithBeforeStopClean(e.registerer.Close))
+		// 先注销客户端的消费方注册，消费方可能使用同一个注册中心
+		options = append(options, WithBeforeStopClean(eregistry.UnregisterConsumers, func() error {
+			return e.registerer.Close()
+		}))
 	}
 
 	// 设置运行后清理函数
@@ -103,6 +107,7 @@ func New(options ...Option) *Ego {
 		loadConfig,
 		initMaxProcs,
 		e.initLogger,
+		e.initMetric,
 		e.initTracer,
 	}
 
@@ -123,8 +128,11 @@ func (e *Ego) Invoker(fns ...func() error) *Ego {
 	return e
 }
 
-// Registry 设置注册中心
+// Registry 设置注册中心，注册的服务会定期续约，注册失败或者注册丢失时自动重新注册
 func (e *Ego) Registry(reg eregistry.Registry) *Ego {
+	if _, ok := reg.(eregistry.Nop); !ok {
+		reg = eregistry.NewLease(reg)
+	}
 	e.registerer = reg
 	return e
 }
@@ -191,7 +199,10 @@ func (e *Ego) Run() error {
 
 	// 如果存在短时任务，那么只执行短时任务
 	if len(e.jobs) > 0 {
-		return e.startJobs()
+		err := e.startJobs()
+		// 短时任务结束后同样运行停止后清理，例如刷新日志、推送指标
+		runSerialFuncLogError(e.opts.afterStopClean)
+		return err
 	}
 
 	e.waitSignals() // start signal listen task in goroutine
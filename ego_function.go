@@ -17,8 +17,11 @@ import (
 	"github.com/gotomicro/ego/core/econf/manager"
 	"github.com/gotomicro/ego/core/eflag"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/emetric/epush"
 	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/etrace/ejaeger"
+	"github.com/gotomicro/ego/core/etrace/eotel"
 	"github.com/gotomicro/ego/core/util/xcolor"
 )
 
@@ -173,16 +176,53 @@ func (e *Ego) initLogger() error {
 	return nil
 }
 
+// initMetric 按metric配置重新构造内置指标
+func (e *Ego) initMetric() error {
+	if econf.Get(e.opts.configPrefix+"metric") != nil {
+		if err := emetric.Load(e.opts.configPrefix + "metric").Build(); err != nil {
+			elog.EgoLogger.Panic("init metric", elog.FieldComponent("app"), elog.FieldErr(err))
+		}
+		elog.EgoLogger.Info("init metric", elog.FieldComponent("app"))
+	}
+	// 配置了metric.push时推送指标，短时任务以job名作为分组label
+	if econf.Get(e.opts.configPrefix+"metric.push") != nil {
+		pusher := epush.Load(e.opts.configPrefix + "metric.push").Build(epush.WithGrouping("job_name", eflag.String("job")))
+		pusher.Start()
+		// 先于日志刷新推送，保证推送失败的日志能够输出
+		e.opts.afterStopClean = append([]func() error{pusher.Stop}, e.opts.afterStopClean...)
+		elog.EgoLogger.Info("init metric pusher", elog.FieldComponent("app"))
+	}
+	return nil
+}
+
 // initTracer init global tracer
 func (e *Ego) initTracer() error {
+	// 配置了trace.sampler时使用动态采样，覆盖各个tracer自身的采样配置
+	var sampler *etrace.Sampler
+	if econf.Get(e.opts.configPrefix+"trace.sampler") != nil {
+		sampler = etrace.LoadSampler(e.opts.configPrefix + "trace.sampler")
+		etrace.SetGlobalSampler(sampler)
+	}
 	if econf.Get(e.opts.configPrefix+"trace.jaeger") != nil {
 		container := ejaeger.Load(e.opts.configPrefix + "trace.jaeger")
+		if sampler != nil {
+			container.WithSampler(sampler)
+		}
+		tracer := container.Build()
+		etrace.SetGlobalTracer(tracer)
+		e.opts.afterStopClean = append(e.opts.afterStopClean, container.Stop)
+		elog.EgoLogger.Info("init trace", elog.FieldComponent("app"), elog.String("backend", "jaeger"))
+	} else if econf.Get(e.opts.configPrefix+"trace.otel") != nil {
+		container := eotel.Load(e.opts.configPrefix + "trace.otel")
+		if sampler != nil {
+			container.WithSampler(sampler)
+		}
 		tracer := container.Build()
 		etrace.SetGlobalTracer(tracer)
 		e.opts.afterStopClean = append(e.opts.afterStopClean, container.Stop)
-		elog.EgoLogger.Info("init trace", elog.FieldComponent("app"))
+		elog.EgoLogger.Info("init trace", elog.FieldComponent("app"), elog.String("backend", "otel"))
 	} else {
-		elog.EgoLogger.Warn("not init trace", elog.FieldValue("need trace config: [trace.jaeger] in config"), elog.FieldComponent("app"))
+		elog.EgoLogger.Warn("not init trace", elog.FieldValue("need trace config: [trace.jaeger] or [trace.otel] in config"), elog.FieldComponent("app"))
 	}
 	return nil
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.uber.org/zap"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func main() {
+	var err error
+	conf := `
+[loki]
+level = "info"
+writer = "loki"
+flushBufferInterval = "2s"
+lokiUrl = "http://127.0.0.1:3100/loki/api/v1/push" # loki push api
+lokiLabels = { env = "dev" }                        # stream labels
+`
+	if err = econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal); err != nil {
+		log.Println("load conf fail", err)
+		return
+	}
+	logger := elog.Load("loki").Build()
+	defer logger.Flush()
+	logger.Info("an loki msg", zap.Any("lee", 17))
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"go.uber.org/zap"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func main() {
+	var err error
+	conf := `
+[syslog]
+level = "info"
+writer = "syslog"
+syslogNetwork = "udp"          # udp|tcp|unix|unixgram
+syslogAddr = "127.0.0.1:514"   # syslog server address
+syslogFacility = "local0"      # syslog facility
+`
+	if err = econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal); err != nil {
+		log.Println("load conf fail", err)
+		return
+	}
+	logger := elog.Load("syslog").Build()
+	defer logger.Flush()
+	logger.Info("an syslog msg", zap.Any("lee", 17))
+}
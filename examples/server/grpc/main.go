@@ -4,9 +4,9 @@ import (
 	"context"
 	"github.com/gotomicro/ego"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/examples/grpc/direct/helloworld"
 	"github.com/gotomicro/ego/server"
 	"github.com/gotomicro/ego/server/egrpc"
-	"google.golang.org/grpc/examples/helloworld/helloworld"
 )
 
 //  export EGO_DEBUG=true && go run main.go --config=config.toml
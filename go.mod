@@ -10,24 +10,34 @@ require (
 	github.com/fsnotify/fsnotify v1.4.9
 	github.com/gin-gonic/gin v1.6.3
 	github.com/go-resty/resty/v2 v2.5.0
-	github.com/golang/protobuf v1.4.2
+	github.com/golang/protobuf v1.5.2 // otlptrace依赖google.golang.org/protobuf v1.27，需要与之兼容的v1.5
 	github.com/gorilla/websocket v1.4.2
 	github.com/json-iterator/go v1.1.10
 	github.com/mitchellh/mapstructure v1.3.2
 	github.com/modern-go/reflect2 v1.0.1
-	github.com/opentracing/opentracing-go v1.1.0
+	github.com/opentracing/opentracing-go v1.2.0 // otel/bridge/opentracing v1.0.0要求的最低版本
 	github.com/pierrec/lz4 v2.6.0+incompatible
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.6.0
+	github.com/prometheus/client_model v0.2.0
 	github.com/robfig/cron/v3 v3.0.1
-	github.com/stretchr/testify v1.6.1
+	github.com/stretchr/testify v1.7.0 // otel v1.0.0各模块要求的最低版本
 	github.com/uber/jaeger-client-go v2.23.1+incompatible
 	github.com/uber/jaeger-lib v2.4.0+incompatible // indirect
+	go.opentelemetry.io/contrib/propagators/b3 v1.0.0
+	go.opentelemetry.io/contrib/propagators/jaeger v1.0.0
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/bridge/opentracing v1.0.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.0.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.0.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.1
 	go.uber.org/automaxprocs v1.3.0
 	go.uber.org/zap v1.15.0
 	golang.org/x/net v0.0.0-20201224014010-6772e930b67b
 	golang.org/x/sync v0.0.0-20200625203802-6e8e738ad208
 	google.golang.org/genproto v0.0.0-20200806141610-86f49bd18e98
-	google.golang.org/grpc v1.29.1
+	google.golang.org/grpc v1.40.0 // otlptrace、otlptracegrpc v1.0.0要求的最低版本，examples因此改用examples/grpc/direct下的helloworld
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
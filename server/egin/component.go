@@ -128,7 +128,7 @@ func (c *Component) Info() *server.ServiceInfo {
 		server.WithAddress(c.listener.Addr().String()),
 		server.WithKind(constant.ServiceProvider),
 	)
-	return &info
+	return server.ApplyOverride(info)
 }
 
 func commentUniqKey(method, path string) string {
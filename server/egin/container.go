@@ -1,10 +1,9 @@
 package egin
 
 import (
-	"github.com/opentracing/opentracing-go"
-
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/util/xnet"
 )
 
@@ -60,7 +59,7 @@ func (c *Container) Build(options ...Option) *Component {
 		server.Use(metricServerInterceptor())
 	}
 
-	if c.config.EnableTraceInterceptor && opentracing.IsGlobalTracerRegistered() {
+	if c.config.EnableTraceInterceptor && etrace.IsGlobalTracerRegistered() {
 		server.Use(traceServerInterceptor())
 	}
 	return server
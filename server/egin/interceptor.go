@@ -12,8 +12,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/opentracing/opentracing-go"
-	"github.com/uber/jaeger-client-go"
 	"go.uber.org/zap"
 
 	"github.com/gotomicro/ego/core/eapp"
@@ -55,7 +53,7 @@ func recoverMiddleware(logger *elog.Component, config *Config) gin.HandlerFunc {
 				elog.FieldPeerIP(getPeerIP(c.Request.RemoteAddr)),
 			)
 
-			if config.EnableTraceInterceptor && opentracing.IsGlobalTracerRegistered() {
+			if config.EnableTraceInterceptor && etrace.IsGlobalTracerRegistered() {
 				fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(c.Request.Context())))
 			}
 
@@ -170,7 +168,7 @@ func metricServerInterceptor() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		beg := time.Now()
 		c.Next()
-		emetric.ServerHandleHistogram.Observe(time.Since(beg).Seconds(), emetric.TypeHTTP, c.Request.Method+"."+c.FullPath(), extractAPP(c))
+		emetric.ServerHandleHistogram.ObserveWithTrace(time.Since(beg).Seconds(), etrace.ExtractTraceID(c.Request.Context()), emetric.TypeHTTP, c.Request.Method+"."+c.FullPath(), extractAPP(c))
 		emetric.ServerHandleCounter.Inc(emetric.TypeHTTP, c.Request.Method+"."+c.FullPath(), extractAPP(c), http.StatusText(c.Writer.Status()))
 	}
 }
@@ -189,8 +187,7 @@ func traceServerInterceptor() gin.HandlerFunc {
 		)
 		c.Request = c.Request.WithContext(ctx)
 		defer span.Finish()
-		// 判断了全局jaeger的设置，所以这里一定能够断言为jaeger
-		c.Header(eapp.EgoTraceIDName(), span.(*jaeger.Span).Context().(jaeger.SpanContext).TraceID().String())
+		c.Header(eapp.EgoTraceIDName(), etrace.ExtractTraceID(ctx))
 		c.Next()
 	}
 }
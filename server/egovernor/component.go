@@ -16,6 +16,8 @@ import (
 	"github.com/gotomicro/ego/core/eapp"
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/eregistry"
 	"github.com/gotomicro/ego/server"
 )
 
@@ -61,6 +63,21 @@ func init() {
 		w.WriteHeader(200)
 		_ = jsoniter.NewEncoder(w).Encode(os.Environ())
 	})
+	// 服务在注册中心的注册状态，存在未注册的服务提供方时返回503，可以作为健康检查
+	HandleFunc("/registry/leases", func(w http.ResponseWriter, r *http.Request) {
+		states := eregistry.LeaseStates()
+		for _, state := range states {
+			if !state.Registered && state.Kind != constant.ServiceConsumer.String() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				break
+			}
+		}
+		_ = json.NewEncoder(w).Encode(states)
+	})
+	// 本应用调用的服务，以及注册中心中调用本应用的消费方，下线服务前可以用来评估影响
+	HandleFunc("/registry/topology", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(eregistry.GetTopology(r.Context()))
+	})
 	HandleFunc("/build/info", func(w http.ResponseWriter, r *http.Request) {
 		serverStats := map[string]string{
 			"name":       eapp.Name(),
@@ -123,7 +140,10 @@ func (c *Component) Init() error {
 // Start 开始
 func (c *Component) Start() error {
 	HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
-		promhttp.Handler().ServeHTTP(w, r)
+		promhttp.InstrumentMetricHandler(emetric.DefaultRegisterer, promhttp.HandlerFor(emetric.DefaultGatherer, promhttp.HandlerOpts{
+			// 请求头Accept为application/openmetrics-text时返回OpenMetrics格式，带上exemplar
+			EnableOpenMetrics: true,
+		})).ServeHTTP(w, r)
 	})
 	err := c.Server.Serve(c.listener)
 	if err == http.ErrServerClosed {
@@ -63,14 +63,18 @@ func (c *Component) Init() error {
 		server.WithScheme("grpc"),
 		server.WithAddress(listener.Addr().String()),
 		server.WithKind(constant.ServiceProvider),
+		// Init在Start之前调用，此时服务已经注册完成
+		server.WithServices(grpcServices(c.Server)),
 	)
 	c.listener = listener
 	c.serverInfo = &info
+	storeServiceInfo(info)
 	return nil
 }
 
 // Start implements server.Component interface.
 func (c *Component) Start() error {
+	defer deleteServiceInfo(c.serverInfo.Address)
 	err := c.Server.Serve(c.listener)
 	return err
 }
@@ -102,7 +106,7 @@ func (c *Component) GracefulStop(ctx context.Context) error {
 
 // Info returns server info, used by governor and consumer balancer
 func (c *Component) Info() *server.ServiceInfo {
-	return c.serverInfo
+	return server.ApplyOverride(*c.serverInfo)
 }
 
 // Address 服务地址
@@ -0,0 +1,60 @@
+package egrpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/gotomicro/ego/server"
+	"github.com/gotomicro/ego/server/egovernor"
+)
+
+func TestServiceInfo(t *testing.T) {
+	container := DefaultContainer()
+	container.config.Host = "127.0.0.1"
+	container.config.Port = 0
+	component := container.Build()
+	grpc_health_v1.RegisterHealthServer(component.Server, health.NewServer())
+	require.NoError(t, component.Init())
+
+	info := component.Info()
+	require.Len(t, info.Services, 1)
+	service := info.Services["grpc.health.v1.Health"]
+	require.NotNil(t, service)
+	assert.Equal(t, "grpc.health.v1", service.Namespace)
+	assert.Equal(t, "Health", service.Name)
+	assert.Equal(t, []string{"Check", "Watch"}, service.Methods)
+	assert.Equal(t, "grpc/health/v1/health.proto", service.Labels["file"])
+	assert.True(t, info.HasMethod("/grpc.health.v1.Health/Check"))
+	assert.False(t, info.HasMethod("/grpc.health.v1.Health/List"))
+	assert.False(t, info.HasMethod("/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"))
+
+	go func() {
+		_ = component.Start()
+	}()
+	assert.Contains(t, ServiceInfos(), *info)
+
+	// 通过governor查询
+	rec := httptest.NewRecorder()
+	egovernor.DefaultServeMux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/grpc/services?method=/grpc.health.v1.Health/Check", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var infos []server.ServiceInfo
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&infos))
+	require.Len(t, infos, 1)
+	assert.Equal(t, info.Address, infos[0].Address)
+	rec = httptest.NewRecorder()
+	egovernor.DefaultServeMux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/grpc/services?method=/grpc.health.v1.Health/List", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	component.Stop()
+	assert.Eventually(t, func() bool {
+		return len(ServiceInfos()) == 0
+	}, time.Second, 10*time.Millisecond)
+}
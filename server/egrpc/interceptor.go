@@ -9,7 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -32,10 +31,10 @@ func prometheusUnaryServerInterceptor(ctx context.Context, req interface{}, info
 	// 只记录系统级别错误
 	if code.Code < ecode.EcodeNum {
 		// 只记录系统级别的详细错误码
-		emetric.ServerHandleHistogram.Observe(time.Since(startTime).Seconds(), emetric.TypeGRPCUnary, info.FullMethod, extractApp(ctx))
+		emetric.ServerHandleHistogram.ObserveWithTrace(time.Since(startTime).Seconds(), etrace.ExtractTraceID(ctx), emetric.TypeGRPCUnary, info.FullMethod, extractApp(ctx))
 		emetric.ServerHandleCounter.Inc(emetric.TypeGRPCUnary, info.FullMethod, extractApp(ctx), code.GetMessage())
 	} else {
-		emetric.ServerHandleHistogram.Observe(time.Since(startTime).Seconds(), emetric.TypeGRPCUnary, info.FullMethod, extractApp(ctx))
+		emetric.ServerHandleHistogram.ObserveWithTrace(time.Since(startTime).Seconds(), etrace.ExtractTraceID(ctx), emetric.TypeGRPCUnary, info.FullMethod, extractApp(ctx))
 		emetric.ServerHandleCounter.Inc(emetric.TypeGRPCUnary, info.FullMethod, extractApp(ctx), "biz error")
 	}
 
@@ -46,7 +45,7 @@ func prometheusStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, in
 	startTime := time.Now()
 	err := handler(srv, ss)
 	code := ecode.ExtractCodes(err)
-	emetric.ServerHandleHistogram.Observe(time.Since(startTime).Seconds(), emetric.TypeGRPCStream, info.FullMethod, extractApp(ss.Context()))
+	emetric.ServerHandleHistogram.ObserveWithTrace(time.Since(startTime).Seconds(), etrace.ExtractTraceID(ss.Context()), emetric.TypeGRPCStream, info.FullMethod, extractApp(ss.Context()))
 	emetric.ServerHandleCounter.Inc(emetric.TypeGRPCStream, info.FullMethod, extractApp(ss.Context()), code.GetMessage())
 	return err
 }
@@ -190,7 +189,7 @@ func defaultUnaryServerInterceptor(logger *elog.Component, config *Config) grpc.
 				elog.FieldPeerIP(getPeerIP(ctx)),
 			)
 
-			if config.EnableTraceInterceptor && opentracing.IsGlobalTracerRegistered() {
+			if config.EnableTraceInterceptor && etrace.IsGlobalTracerRegistered() {
 				fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
 			}
 
@@ -0,0 +1,96 @@
+package egrpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/gotomicro/ego/server"
+	"github.com/gotomicro/ego/server/egovernor"
+)
+
+// reflectionServicePrefix 默认注册的反射服务，不对外发布
+const reflectionServicePrefix = "grpc.reflection."
+
+var (
+	infosMu sync.RWMutex
+	infos   = make(map[string]server.ServiceInfo) // 地址 => 已启动的服务信息
+)
+
+func init() {
+	egovernor.HandleFunc("/grpc/services", handleServices)
+}
+
+// handleServices 本进程提供的grpc服务和方法，带上method参数时只返回提供该方法的服务，例如?method=/helloworld.Greeter/SayHello
+func handleServices(w http.ResponseWriter, r *http.Request) {
+	infos := ServiceInfos()
+	if method := r.URL.Query().Get("method"); method != "" {
+		matched := make([]server.ServiceInfo, 0, len(infos))
+		for _, info := range infos {
+			if info.HasMethod(method) {
+				matched = append(matched, info)
+			}
+		}
+		if len(matched) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		infos = matched
+	}
+	_ = json.NewEncoder(w).Encode(infos)
+}
+
+// ServiceInfos 返回本进程已经启动的grpc服务信息，按地址排序，供治理服务查询
+func ServiceInfos() []server.ServiceInfo {
+	infosMu.RLock()
+	defer infosMu.RUnlock()
+	list := make([]server.ServiceInfo, 0, len(infos))
+	for _, info := range infos {
+		list = append(list, *server.ApplyOverride(info))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Address < list[j].Address })
+	return list
+}
+
+func storeServiceInfo(info server.ServiceInfo) {
+	infosMu.Lock()
+	defer infosMu.Unlock()
+	infos[info.Address] = info
+}
+
+func deleteServiceInfo(address string) {
+	infosMu.Lock()
+	defer infosMu.Unlock()
+	delete(infos, address)
+}
+
+// grpcServices 读取grpc.Server上注册的服务和方法，key为服务全名，例如helloworld.Greeter
+func grpcServices(s *grpc.Server) map[string]*server.Service {
+	services := make(map[string]*server.Service)
+	for fullName, info := range s.GetServiceInfo() {
+		if strings.HasPrefix(fullName, reflectionServicePrefix) {
+			continue
+		}
+		service := &server.Service{
+			Name:    fullName,
+			Labels:  make(map[string]string),
+			Methods: make([]string, 0, len(info.Methods)),
+		}
+		if i := strings.LastIndex(fullName, "."); i >= 0 {
+			service.Namespace, service.Name = fullName[:i], fullName[i+1:]
+		}
+		// protoc生成的代码中Metadata为proto文件名
+		if file, ok := info.Metadata.(string); ok && file != "" {
+			service.Labels["file"] = file
+		}
+		for _, method := range info.Methods {
+			service.Methods = append(service.Methods, method.Name)
+		}
+		sort.Strings(service.Methods)
+		services[fullName] = service
+	}
+	return services
+}
@@ -0,0 +1,35 @@
+package server
+
+import "sync"
+
+var (
+	overridesMu sync.RWMutex
+	overrides   = make(map[string]func(info *ServiceInfo)) // Label => 运行时对服务信息的修改
+)
+
+// SetOverride 设置运行时对服务信息的修改，例如注册中心下发的enable、metadata，fn为nil时删除
+func SetOverride(label string, fn func(info *ServiceInfo)) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	if fn == nil {
+		delete(overrides, label)
+		return
+	}
+	overrides[label] = fn
+}
+
+// ApplyOverride 返回应用运行时修改后的服务信息，不修改传入的info
+func ApplyOverride(info ServiceInfo) *ServiceInfo {
+	overridesMu.RLock()
+	fn, ok := overrides[info.Label()]
+	overridesMu.RUnlock()
+	if ok {
+		metadata := make(map[string]string, len(info.Metadata))
+		for k, v := range info.Metadata {
+			metadata[k] = v
+		}
+		info.Metadata = metadata
+		fn(&info)
+	}
+	return &info
+}
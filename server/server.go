@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gotomicro/ego/core/constant"
 	"github.com/gotomicro/ego/core/eapp"
@@ -13,6 +14,9 @@ import (
 // Option 可选项
 type Option func(c *ServiceInfo)
 
+// DefaultWeight 实例的默认权重，没有服务信息的节点(例如直连地址、静态地址)也使用该权重
+const DefaultWeight = 100
+
 // ConfigInfo represents service configurator
 type ConfigInfo struct {
 	Routes []Route
@@ -48,6 +52,24 @@ func (si ServiceInfo) Label() string {
 	return fmt.Sprintf("%s://%s", si.Scheme, si.Address)
 }
 
+// HasMethod 实例是否提供该方法，fullMethod格式为/helloworld.Greeter/SayHello
+func (si ServiceInfo) HasMethod(fullMethod string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(fullMethod, "/"), "/", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	service, ok := si.Services[parts[0]]
+	if !ok {
+		return false
+	}
+	for _, method := range service.Methods {
+		if method == parts[1] {
+			return true
+		}
+	}
+	return false
+}
+
 // Server ...
 type Server interface {
 	standard.Component
@@ -106,6 +128,13 @@ func WithName(name string) Option {
 	}
 }
 
+// WithServices 设置提供的服务，key为服务全名，例如helloworld.Greeter
+func WithServices(services map[string]*Service) Option {
+	return func(c *ServiceInfo) {
+		c.Services = services
+	}
+}
+
 // WithKind 设置类型
 func WithKind(kind constant.ServiceKind) Option {
 	return func(c *ServiceInfo) {
@@ -116,7 +145,7 @@ func WithKind(kind constant.ServiceKind) Option {
 func defaultServiceInfo() ServiceInfo {
 	si := ServiceInfo{
 		Name:       eapp.Name(),
-		Weight:     100,
+		Weight:     DefaultWeight,
 		Enable:     true,
 		Healthy:    true,
 		Metadata:   make(map[string]string),
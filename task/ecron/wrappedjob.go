@@ -6,7 +6,6 @@ import (
 	"runtime"
 	"time"
 
-	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
 
 	"github.com/gotomicro/ego/core/elog"
@@ -34,7 +33,7 @@ func (wj wrappedJob) run() {
 	emetric.JobHandleCounter.Inc("cron", wj.Name(), "begin")
 	var fields = []elog.Field{zap.String("name", wj.Name())}
 	// 如果设置了链路，增加链路信息
-	if opentracing.IsGlobalTracerRegistered() {
+	if etrace.IsGlobalTracerRegistered() {
 		fields = append(fields, elog.FieldTid(traceID))
 	}
 